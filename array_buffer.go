@@ -0,0 +1,329 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include <stdlib.h>
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// ArrayBuffer is a JavaScript `ArrayBuffer`: a fixed-length block of raw binary data.
+// Its memory is a V8 BackingStore, which Go code can read and write directly via Bytes.
+type ArrayBuffer struct {
+	*Object
+}
+
+// SharedArrayBuffer is a JavaScript `SharedArrayBuffer`: like an ArrayBuffer, except that its
+// memory can be shared by multiple Isolates running on different goroutines (see ShareWith),
+// which can coordinate access with the `Atomics` functions.
+type SharedArrayBuffer struct {
+	*Object
+}
+
+// ArrayBufferLike is implemented by ArrayBuffer and SharedArrayBuffer, the types that a
+// TypedArray can be a view of.
+type ArrayBufferLike interface {
+	Valuer
+	ByteLength() int
+	Bytes() []byte
+}
+
+// TypedArray is a JavaScript typed array, such as a `Uint8Array` or `Float64Array`;
+// a view of a range of an ArrayBuffer as an array of numbers.
+type TypedArray struct {
+	*Object
+}
+
+// TypedArrayKind identifies the element type of a TypedArray.
+type TypedArrayKind int
+
+// This MUST be kept in sync with `TypedArrayKind` in v8go.h!
+const (
+	Uint8ArrayKind TypedArrayKind = iota
+	Uint8ClampedArrayKind
+	Int8ArrayKind
+	Uint16ArrayKind
+	Int16ArrayKind
+	Uint32ArrayKind
+	Int32ArrayKind
+	Float32ArrayKind
+	Float64ArrayKind
+	BigInt64ArrayKind
+	BigUint64ArrayKind
+)
+
+// ElementSize returns the size in bytes of one element of a TypedArray of this kind.
+func (k TypedArrayKind) ElementSize() int {
+	switch k {
+	case Uint8ArrayKind, Uint8ClampedArrayKind, Int8ArrayKind:
+		return 1
+	case Uint16ArrayKind, Int16ArrayKind:
+		return 2
+	case Uint32ArrayKind, Int32ArrayKind, Float32ArrayKind:
+		return 4
+	case Float64ArrayKind, BigInt64ArrayKind, BigUint64ArrayKind:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// Upper bound for slices aliasing C memory; actual slices are cut to their real length.
+const kMaxByteSliceLen = 1 << 40
+
+func bytesAt(data unsafe.Pointer, length C.size_t) []byte {
+	if data == nil || length == 0 {
+		return nil
+	}
+	return (*[kMaxByteSliceLen]byte)(data)[:length:length]
+}
+
+// NewArrayBuffer creates a new ArrayBuffer in the given Context, containing a copy of `data`.
+// This is the only copy made: afterwards, Bytes accesses the buffer's memory in place.
+func NewArrayBuffer(ctx *Context, data []byte) (*ArrayBuffer, error) {
+	if ctx == nil {
+		return nil, errors.New("v8go: Context is required")
+	}
+	var dataPtr unsafe.Pointer
+	if len(data) > 0 {
+		dataPtr = unsafe.Pointer(&data[0])
+	}
+	rtn := C.NewArrayBuffer(ctx.ptr, dataPtr, C.size_t(len(data)))
+	obj, err := objectResult(ctx, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &ArrayBuffer{obj}, nil
+}
+
+// ByteLength returns the size of the buffer in bytes. It's zero if the buffer has been detached.
+func (b *ArrayBuffer) ByteLength() int {
+	return int(C.ArrayBufferGetContents(b.valuePtr()).length)
+}
+
+// Bytes returns a slice that points directly to the buffer's memory; no copying is done, and
+// changes made through the slice are visible to JavaScript and vice versa.
+// The slice is only valid while the ArrayBuffer is reachable (i.e. until its Context is closed,
+// or the WithTemporaryValues call that created it returns) and until it's detached.
+// Accessing it after that will read or corrupt freed memory.
+func (b *ArrayBuffer) Bytes() []byte {
+	contents := C.ArrayBufferGetContents(b.valuePtr())
+	return bytesAt(contents.data, contents.length)
+}
+
+// IsDetachable returns true if the buffer can be detached.
+func (b *ArrayBuffer) IsDetachable() bool {
+	return C.ArrayBufferIsDetachable(b.valuePtr()) != 0
+}
+
+// Detach detaches the buffer and all views on it (typed arrays), setting their lengths to zero
+// so that JavaScript can no longer access the memory.
+// Any slice previously returned by Bytes must not be used afterwards.
+func (b *ArrayBuffer) Detach() error {
+	if C.ArrayBufferDetach(b.valuePtr()) == 0 {
+		return errors.New("v8go: ArrayBuffer is not detachable")
+	}
+	return nil
+}
+
+// Transfer moves the buffer's memory, without copying, to a new ArrayBuffer in another
+// Context, which may belong to a different Isolate. This buffer is detached.
+func (b *ArrayBuffer) Transfer(ctx *Context) (*ArrayBuffer, error) {
+	if ctx == nil {
+		return nil, errors.New("v8go: Context is required")
+	}
+	if !b.IsDetachable() {
+		return nil, errors.New("v8go: ArrayBuffer is not detachable")
+	}
+	rtn := C.ArrayBufferTransfer(b.valuePtr(), ctx.ptr)
+	obj, err := objectResult(ctx, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &ArrayBuffer{obj}, nil
+}
+
+// NewSharedArrayBuffer creates a new zero-filled SharedArrayBuffer of the given length in bytes.
+func NewSharedArrayBuffer(ctx *Context, length int) (*SharedArrayBuffer, error) {
+	if ctx == nil {
+		return nil, errors.New("v8go: Context is required")
+	}
+	if length < 0 {
+		return nil, errors.New("v8go: SharedArrayBuffer length must not be negative")
+	}
+	rtn := C.NewSharedArrayBuffer(ctx.ptr, C.size_t(length))
+	obj, err := objectResult(ctx, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedArrayBuffer{obj}, nil
+}
+
+// ByteLength returns the size of the buffer in bytes.
+func (b *SharedArrayBuffer) ByteLength() int {
+	return int(C.ArrayBufferGetContents(b.valuePtr()).length)
+}
+
+// Bytes returns a slice that points directly to the buffer's memory, as with ArrayBuffer.Bytes.
+// The memory stays valid as long as any SharedArrayBuffer using it is reachable, but
+// other Isolates may be modifying it concurrently; use the `sync/atomic` package to
+// access it in coordination with JavaScript's `Atomics`.
+func (b *SharedArrayBuffer) Bytes() []byte {
+	contents := C.ArrayBufferGetContents(b.valuePtr())
+	return bytesAt(contents.data, contents.length)
+}
+
+// ShareWith creates a SharedArrayBuffer in another Context, usually belonging to a different
+// Isolate, that shares this buffer's memory. This is how parallel workers on multiple
+// goroutines can operate on the same data.
+func (b *SharedArrayBuffer) ShareWith(ctx *Context) (*SharedArrayBuffer, error) {
+	if ctx == nil {
+		return nil, errors.New("v8go: Context is required")
+	}
+	rtn := C.SharedArrayBufferShareWith(b.valuePtr(), ctx.ptr)
+	obj, err := objectResult(ctx, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedArrayBuffer{obj}, nil
+}
+
+// NewTypedArray creates a TypedArray of the given kind that views `length` elements
+// of the buffer (an ArrayBuffer or SharedArrayBuffer), starting at byte offset `byteOffset`.
+// The offset must be a multiple of the element size, and the range must fit in the buffer.
+func NewTypedArray(kind TypedArrayKind, buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	elemSize := kind.ElementSize()
+	if elemSize == 0 {
+		return nil, fmt.Errorf("v8go: invalid TypedArrayKind %d", kind)
+	}
+	if isNilBuffer(buf) {
+		return nil, errors.New("v8go: ArrayBuffer is required")
+	}
+	if byteOffset < 0 || length < 0 {
+		return nil, errors.New("v8go: TypedArray offset and length must not be negative")
+	}
+	if byteOffset%elemSize != 0 {
+		return nil, fmt.Errorf("v8go: TypedArray offset %d is not a multiple of the element size %d", byteOffset, elemSize)
+	}
+	if bufLen := buf.ByteLength(); byteOffset > bufLen || length > (bufLen-byteOffset)/elemSize {
+		return nil, fmt.Errorf("v8go: TypedArray range [%d, +%d×%d) is out of ArrayBuffer bounds (%d bytes)",
+			byteOffset, length, elemSize, bufLen)
+	}
+	val := buf.value()
+	rtn := C.NewTypedArray(val.valuePtr(), C.int(kind), C.size_t(byteOffset), C.size_t(length))
+	obj, err := objectResult(val.ctx, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedArray{obj}, nil
+}
+
+// Returns true if buf is nil, including a nil *ArrayBuffer or *SharedArrayBuffer.
+func isNilBuffer(buf ArrayBufferLike) bool {
+	switch b := buf.(type) {
+	case nil:
+		return true
+	case *ArrayBuffer:
+		return b == nil || b.Object == nil
+	case *SharedArrayBuffer:
+		return b == nil || b.Object == nil
+	}
+	return false
+}
+
+// NewUint8Array creates a `Uint8Array` viewing part of a buffer; see NewTypedArray.
+func NewUint8Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(Uint8ArrayKind, buf, byteOffset, length)
+}
+
+// NewUint8ClampedArray creates a `Uint8ClampedArray` viewing part of a buffer; see NewTypedArray.
+func NewUint8ClampedArray(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(Uint8ClampedArrayKind, buf, byteOffset, length)
+}
+
+// NewInt8Array creates an `Int8Array` viewing part of a buffer; see NewTypedArray.
+func NewInt8Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(Int8ArrayKind, buf, byteOffset, length)
+}
+
+// NewUint16Array creates a `Uint16Array` viewing part of a buffer; see NewTypedArray.
+func NewUint16Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(Uint16ArrayKind, buf, byteOffset, length)
+}
+
+// NewInt16Array creates an `Int16Array` viewing part of a buffer; see NewTypedArray.
+func NewInt16Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(Int16ArrayKind, buf, byteOffset, length)
+}
+
+// NewUint32Array creates a `Uint32Array` viewing part of a buffer; see NewTypedArray.
+func NewUint32Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(Uint32ArrayKind, buf, byteOffset, length)
+}
+
+// NewInt32Array creates an `Int32Array` viewing part of a buffer; see NewTypedArray.
+func NewInt32Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(Int32ArrayKind, buf, byteOffset, length)
+}
+
+// NewFloat32Array creates a `Float32Array` viewing part of a buffer; see NewTypedArray.
+func NewFloat32Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(Float32ArrayKind, buf, byteOffset, length)
+}
+
+// NewFloat64Array creates a `Float64Array` viewing part of a buffer; see NewTypedArray.
+func NewFloat64Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(Float64ArrayKind, buf, byteOffset, length)
+}
+
+// NewBigInt64Array creates a `BigInt64Array` viewing part of a buffer; see NewTypedArray.
+func NewBigInt64Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(BigInt64ArrayKind, buf, byteOffset, length)
+}
+
+// NewBigUint64Array creates a `BigUint64Array` viewing part of a buffer; see NewTypedArray.
+func NewBigUint64Array(buf ArrayBufferLike, byteOffset, length int) (*TypedArray, error) {
+	return NewTypedArray(BigUint64ArrayKind, buf, byteOffset, length)
+}
+
+// Buffer returns the buffer this array is a view of: an *ArrayBuffer or a *SharedArrayBuffer.
+func (t *TypedArray) Buffer() ArrayBufferLike {
+	ref := C.ArrayBufferViewBuffer(t.valuePtr())
+	obj := &Object{&Value{ref, t.ctx}}
+	if obj.IsSharedArrayBuffer() {
+		return &SharedArrayBuffer{obj}
+	}
+	return &ArrayBuffer{obj}
+}
+
+// ByteOffset returns the offset in bytes of the start of this array within its ArrayBuffer.
+func (t *TypedArray) ByteOffset() int {
+	return int(C.ArrayBufferViewByteOffset(t.valuePtr()))
+}
+
+// ByteLength returns the size of this array in bytes.
+func (t *TypedArray) ByteLength() int {
+	return int(C.ArrayBufferViewByteLength(t.valuePtr()))
+}
+
+// Length returns the number of elements in this array.
+func (t *TypedArray) Length() int {
+	return int(C.TypedArrayLength(t.valuePtr()))
+}
+
+// Bytes returns a slice that points directly to the range of the ArrayBuffer viewed by this
+// array. The same caveats apply as with ArrayBuffer.Bytes and SharedArrayBuffer.Bytes.
+func (t *TypedArray) Bytes() []byte {
+	bytes := t.Buffer().Bytes()
+	offset, length := t.ByteOffset(), t.ByteLength()
+	if offset+length > len(bytes) {
+		return nil
+	}
+	return bytes[offset : offset+length : offset+length]
+}
@@ -0,0 +1,319 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestArrayBuffer(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	data := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	buf, err := v8.NewArrayBuffer(ctx, data)
+	fatalIf(t, err)
+	if !buf.IsArrayBuffer() {
+		t.Fatal("expected IsArrayBuffer to be true")
+	}
+	if n := buf.ByteLength(); n != len(data) {
+		t.Errorf("unexpected ByteLength: %d", n)
+	}
+	if b := buf.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("unexpected Bytes: %v", b)
+	}
+
+	// Changes made from JS are visible in the Go slice, and vice versa:
+	fatalIf(t, ctx.Global().Set("buf", buf))
+	view := buf.Bytes()
+	_, err = ctx.RunScript("new Uint8Array(buf)[0] = 99", "")
+	fatalIf(t, err)
+	if view[0] != 99 {
+		t.Errorf("expected JS write to be visible in Go, got %d", view[0])
+	}
+	view[1] = 42
+	val, err := ctx.RunScript("new Uint8Array(buf)[1]", "")
+	fatalIf(t, err)
+	if val.Int32() != 42 {
+		t.Errorf("expected Go write to be visible in JS, got %v", val)
+	}
+
+	empty, err := v8.NewArrayBuffer(ctx, nil)
+	fatalIf(t, err)
+	if empty.ByteLength() != 0 || len(empty.Bytes()) != 0 {
+		t.Errorf("expected empty ArrayBuffer")
+	}
+
+	if _, err := v8.NewArrayBuffer(nil, data); err == nil {
+		t.Error("expected error with nil Context")
+	}
+}
+
+func TestArrayBufferDetach(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	buf, err := v8.NewArrayBuffer(ctx, make([]byte, 16))
+	fatalIf(t, err)
+	arr, err := v8.NewUint8Array(buf, 0, 16)
+	fatalIf(t, err)
+	if !buf.IsDetachable() {
+		t.Fatal("expected ArrayBuffer to be detachable")
+	}
+	fatalIf(t, buf.Detach())
+	if n := buf.ByteLength(); n != 0 {
+		t.Errorf("expected detached buffer to be empty, got length %d", n)
+	}
+	if n := arr.Length(); n != 0 {
+		t.Errorf("expected view of detached buffer to be empty, got length %d", n)
+	}
+}
+
+func TestTypedArray(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	buf, err := v8.NewArrayBuffer(ctx, make([]byte, 64))
+	fatalIf(t, err)
+
+	tests := [...]struct {
+		kind   v8.TypedArrayKind
+		newFn  func(v8.ArrayBufferLike, int, int) (*v8.TypedArray, error)
+		offset int
+		length int
+		is     func(*v8.Value) bool
+	}{
+		{v8.Uint8ArrayKind, v8.NewUint8Array, 3, 10, (*v8.Value).IsUint8Array},
+		{v8.Uint8ClampedArrayKind, v8.NewUint8ClampedArray, 0, 64, (*v8.Value).IsUint8ClampedArray},
+		{v8.Int8ArrayKind, v8.NewInt8Array, 1, 1, (*v8.Value).IsInt8Array},
+		{v8.Uint16ArrayKind, v8.NewUint16Array, 2, 4, (*v8.Value).IsUint16Array},
+		{v8.Int16ArrayKind, v8.NewInt16Array, 4, 30, (*v8.Value).IsInt16Array},
+		{v8.Uint32ArrayKind, v8.NewUint32Array, 8, 2, (*v8.Value).IsUint32Array},
+		{v8.Int32ArrayKind, v8.NewInt32Array, 60, 1, (*v8.Value).IsInt32Array},
+		{v8.Float32ArrayKind, v8.NewFloat32Array, 0, 16, (*v8.Value).IsFloat32Array},
+		{v8.Float64ArrayKind, v8.NewFloat64Array, 8, 7, (*v8.Value).IsFloat64Array},
+		{v8.BigInt64ArrayKind, v8.NewBigInt64Array, 16, 0, (*v8.Value).IsBigInt64Array},
+		{v8.BigUint64ArrayKind, v8.NewBigUint64Array, 0, 8, (*v8.Value).IsBigUint64Array},
+	}
+	for _, tt := range tests {
+		arr, err := tt.newFn(buf, tt.offset, tt.length)
+		fatalIf(t, err)
+		if !arr.IsTypedArray() || !tt.is(arr.Value) {
+			t.Errorf("kind %d: wrong array type %s", tt.kind, arr.DetailString())
+		}
+		if n := arr.Length(); n != tt.length {
+			t.Errorf("kind %d: unexpected Length %d", tt.kind, n)
+		}
+		if n := arr.ByteOffset(); n != tt.offset {
+			t.Errorf("kind %d: unexpected ByteOffset %d", tt.kind, n)
+		}
+		if n := arr.ByteLength(); n != tt.length*tt.kind.ElementSize() {
+			t.Errorf("kind %d: unexpected ByteLength %d", tt.kind, n)
+		}
+		if n := len(arr.Bytes()); n != arr.ByteLength() {
+			t.Errorf("kind %d: unexpected len(Bytes) %d", tt.kind, n)
+		}
+		if b, ok := arr.Buffer().(*v8.ArrayBuffer); !ok || !b.SameValue(buf.Value) {
+			t.Errorf("kind %d: Buffer is not the original ArrayBuffer", tt.kind)
+		}
+	}
+
+	if _, err := v8.NewFloat64Array(buf, 4, 1); err == nil {
+		t.Error("expected error for misaligned offset")
+	}
+	if _, err := v8.NewUint32Array(buf, 8, 15); err == nil {
+		t.Error("expected error for out-of-bounds length")
+	}
+	if _, err := v8.NewUint8Array(buf, 65, 0); err == nil {
+		t.Error("expected error for out-of-bounds offset")
+	}
+	var nilBuf *v8.ArrayBuffer
+	if _, err := v8.NewUint8Array(nilBuf, 0, 0); err == nil {
+		t.Error("expected error for a nil *ArrayBuffer")
+	}
+}
+
+func TestTypedArrayFromJS(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.RunScript("new Uint16Array([1, 2, 0x1234]).subarray(1)", "")
+	fatalIf(t, err)
+	arr, err := val.AsTypedArray()
+	fatalIf(t, err)
+	if arr.Length() != 2 || arr.ByteOffset() != 2 || arr.ByteLength() != 4 {
+		t.Errorf("unexpected geometry: length %d, offset %d, byteLength %d",
+			arr.Length(), arr.ByteOffset(), arr.ByteLength())
+	}
+	if b := arr.Bytes(); !bytes.Equal(b, []byte{2, 0, 0x34, 0x12}) {
+		t.Errorf("unexpected Bytes: %v", b)
+	}
+	if _, ok := arr.Buffer().(*v8.ArrayBuffer); !ok {
+		t.Errorf("expected Buffer to be an *ArrayBuffer, got %T", arr.Buffer())
+	}
+
+	if _, err := val.AsArrayBuffer(); err == nil {
+		t.Error("expected error casting a Uint16Array to ArrayBuffer")
+	}
+	notArray, _ := ctx.RunScript("[1, 2]", "")
+	if _, err := notArray.AsTypedArray(); err == nil {
+		t.Error("expected error casting an Array to TypedArray")
+	}
+}
+
+func TestSharedArrayBuffer(t *testing.T) {
+	t.Parallel()
+	iso1 := v8.NewIsolate()
+	defer iso1.Dispose()
+	ctx1 := v8.NewContext(iso1)
+	defer ctx1.Close()
+	iso2 := v8.NewIsolate()
+	defer iso2.Dispose()
+	ctx2 := v8.NewContext(iso2)
+	defer ctx2.Close()
+
+	sab1, err := v8.NewSharedArrayBuffer(ctx1, 16)
+	fatalIf(t, err)
+	if !sab1.IsSharedArrayBuffer() || sab1.ByteLength() != 16 {
+		t.Fatalf("unexpected SharedArrayBuffer %s", sab1.DetailString())
+	}
+	sab2, err := sab1.ShareWith(ctx2)
+	fatalIf(t, err)
+	if sab2.ByteLength() != 16 || &sab2.Bytes()[0] != &sab1.Bytes()[0] {
+		t.Fatal("expected ShareWith to share the same memory")
+	}
+
+	arr, err := v8.NewInt32Array(sab1, 4, 2)
+	fatalIf(t, err)
+	if _, ok := arr.Buffer().(*v8.SharedArrayBuffer); !ok {
+		t.Errorf("expected Buffer to be a *SharedArrayBuffer, got %T", arr.Buffer())
+	}
+
+	fatalIf(t, ctx1.Global().Set("shared", arr))
+	fatalIf(t, ctx2.Global().Set("shared", sab2))
+	_, err = ctx1.RunScript("Atomics.store(shared, 1, 1234)", "")
+	fatalIf(t, err)
+	val, err := ctx2.RunScript("Atomics.load(new Int32Array(shared), 2)", "")
+	fatalIf(t, err)
+	if val.Int32() != 1234 {
+		t.Errorf("expected value written by other isolate, got %v", val)
+	}
+
+	if _, err := val.AsSharedArrayBuffer(); err == nil {
+		t.Error("expected error casting a number to SharedArrayBuffer")
+	}
+}
+
+func TestSharedArrayBufferAtomicsWait(t *testing.T) {
+	t.Parallel()
+	iso1 := v8.NewIsolate()
+	defer iso1.Dispose()
+	ctx1 := v8.NewContext(iso1)
+	defer ctx1.Close()
+	iso2 := v8.NewIsolate()
+	defer iso2.Dispose()
+	ctx2 := v8.NewContext(iso2)
+	defer ctx2.Close()
+
+	sab, err := v8.NewSharedArrayBuffer(ctx1, 4)
+	fatalIf(t, err)
+	sab2, err := sab.ShareWith(ctx2)
+	fatalIf(t, err)
+	fatalIf(t, ctx1.Global().Set("shared", sab))
+	fatalIf(t, ctx2.Global().Set("shared", sab2))
+
+	// The worker isolate waits until the main one notifies it:
+	done := make(chan string)
+	go func() {
+		iso2.Lock()
+		val, err := ctx2.RunScript("Atomics.wait(new Int32Array(shared), 0, 0, 10000)", "worker.js")
+		result := ""
+		if err != nil {
+			result = err.Error()
+		} else {
+			result = val.String()
+		}
+		iso2.Unlock()
+		done <- result
+	}()
+
+	woken, err := ctx1.RunScript(`
+		const a = new Int32Array(shared);
+		const deadline = Date.now() + 10000;
+		let n = 0;
+		while (n == 0 && Date.now() < deadline) {
+			n = Atomics.notify(a, 0);
+		}
+		n`, "main.js")
+	fatalIf(t, err)
+	if woken.Int32() != 1 {
+		t.Errorf("expected to wake 1 waiter, woke %v", woken)
+	}
+	if result := <-done; result != "ok" {
+		t.Errorf("unexpected result from worker: %q", result)
+	}
+
+	iso2.SetAllowAtomicsWait(false)
+	if _, err := ctx2.RunScript("Atomics.wait(new Int32Array(shared), 0, 0, 0)", ""); err == nil {
+		t.Error("expected Atomics.wait to fail when disallowed")
+	}
+}
+
+func TestSharedArrayBufferWorkerPool(t *testing.T) {
+	t.Parallel()
+	const nWorkers = 4
+	const nPerWorker = 1000
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	sab, err := v8.NewSharedArrayBuffer(ctx, 4*nWorkers*nPerWorker)
+	fatalIf(t, err)
+
+	// Each worker fills its own slice of the shared array, in parallel:
+	errs := make(chan error, nWorkers)
+	for w := 0; w < nWorkers; w++ {
+		wIso := v8.NewIsolate()
+		defer wIso.Dispose()
+		wCtx := v8.NewContext(wIso)
+		defer wCtx.Close()
+		wSab, err := sab.ShareWith(wCtx)
+		fatalIf(t, err)
+		arr, err := v8.NewInt32Array(wSab, 4*w*nPerWorker, nPerWorker)
+		fatalIf(t, err)
+		fatalIf(t, wCtx.Global().Set("slice", arr))
+		go func(w int) {
+			wIso.Lock()
+			_, err := wCtx.RunScript(fmt.Sprintf(
+				"for (let i = 0; i < slice.length; i++) Atomics.store(slice, i, %d + i)", w*nPerWorker), "")
+			wIso.Unlock()
+			errs <- err
+		}(w)
+	}
+	for w := 0; w < nWorkers; w++ {
+		fatalIf(t, <-errs)
+	}
+
+	fatalIf(t, ctx.Global().Set("all", sab))
+	val, err := ctx.RunScript("new Int32Array(all).reduce((a, b) => a + b, 0)", "")
+	fatalIf(t, err)
+	const n = nWorkers * nPerWorker
+	if val.Integer() != n*(n-1)/2 {
+		t.Errorf("unexpected sum %v", val)
+	}
+}
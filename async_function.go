@@ -0,0 +1,204 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// AsyncFunctionCallback is the Go implementation of a JavaScript function created from a
+// template made by NewAsyncFunctionTemplate. It's called on a new goroutine, so it may block,
+// but it must not use the Isolate or any Values.
+//
+// The arguments are converted to Go: `null` and `undefined` to nil, and booleans, numbers,
+// BigInts, strings and Dates to bool, float64, *big.Int, string and time.Time. Other objects
+// and arrays are converted as by encoding/json, to map[string]interface{} and []interface{}.
+//
+// The result is converted as by Context.NewValue, or else by encoding/json, and resolves the
+// function's Promise; an error rejects it, like throwJSError. The context is canceled when the
+// Isolate is disposed.
+type AsyncFunctionCallback func(ctx context.Context, args []interface{}) (interface{}, error)
+
+// NewAsyncFunctionTemplate creates a FunctionTemplate for a function that returns a Promise,
+// and calls the Go callback on a new goroutine.
+//
+// The callback's result is delivered back to JavaScript on the Isolate's goroutine by
+// Isolate.SettleAsyncCalls, or Isolate.WaitAsyncCalls; an event loop should call one of these
+// when Isolate.AsyncCallsReady signals.
+func NewAsyncFunctionTemplate(iso *Isolate, callback AsyncFunctionCallback) *FunctionTemplate {
+	if callback == nil {
+		panic("nil AsyncFunctionCallback argument not supported")
+	}
+	return NewFunctionTemplate(iso, func(info *FunctionCallbackInfo) *Value {
+		ctx := info.Context()
+		resolver, err := NewPromiseResolver(ctx)
+		if err != nil {
+			return throwJSError(ctx, err)
+		}
+		promise := resolver.GetPromise().Value
+
+		args := make([]interface{}, len(info.Args()))
+		for i, arg := range info.Args() {
+			if args[i], err = goValueOf(arg); err != nil {
+				resolver.Reject(jsErrorValue(ctx, err))
+				return promise
+			}
+		}
+
+		call := &asyncCall{ctx: ctx, resolver: resolver}
+		goCtx := iso.startAsyncCall()
+		go func() {
+			call.result, call.err = callback(goCtx, args)
+			iso.finishAsyncCall(call)
+		}()
+		return promise
+	})
+}
+
+// AsyncCallsReady returns a channel that receives a value when async calls have finished, and
+// SettleAsyncCalls should be called to deliver their results.
+func (i *Isolate) AsyncCallsReady() <-chan struct{} {
+	return i.asyncReady
+}
+
+// SettleAsyncCalls resolves or rejects the Promises of the finished async calls (see
+// NewAsyncFunctionTemplate), then runs the microtasks so that their handlers are called.
+// It returns the number of async calls still running. It must be called on the Isolate's
+// goroutine; calls whose Context has been closed are ignored.
+func (i *Isolate) SettleAsyncCalls() int {
+	i.asyncMutex.Lock()
+	calls := i.asyncResults
+	i.asyncResults = nil
+	i.asyncPending -= len(calls)
+	i.asyncMutex.Unlock()
+
+	for _, call := range calls {
+		if call.ctx.ptr == nil {
+			continue
+		}
+		if call.err == nil {
+			convert := call.convert
+			if convert == nil {
+				convert = asyncResultValue
+			}
+			var val *Value
+			if val, call.err = convert(call.ctx, call.result); call.err == nil {
+				call.resolver.Resolve(val)
+				continue
+			}
+		}
+		call.resolver.Reject(jsErrorValue(call.ctx, call.err))
+	}
+	if len(calls) > 0 {
+		C.IsolatePerformMicrotaskCheckpoint(i.ptr)
+	}
+
+	// Handlers may have started more calls:
+	i.asyncMutex.Lock()
+	defer i.asyncMutex.Unlock()
+	return i.asyncPending
+}
+
+// WaitAsyncCalls settles async calls as they finish, until none are running or ctx is done.
+// It must be called on the Isolate's goroutine.
+func (i *Isolate) WaitAsyncCalls(ctx context.Context) error {
+	for i.SettleAsyncCalls() > 0 {
+		select {
+		case <-i.asyncReady:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// An in-progress call of an AsyncFunctionCallback.
+type asyncCall struct {
+	ctx      *Context
+	resolver *PromiseResolver
+	result   interface{}
+	err      error
+	convert  func(*Context, interface{}) (*Value, error) // Converts result; default asyncResultValue
+}
+
+// Registers a new async call and returns the Go context to pass to it.
+func (i *Isolate) startAsyncCall() context.Context {
+	i.asyncMutex.Lock()
+	defer i.asyncMutex.Unlock()
+	if i.asyncCtx == nil {
+		i.asyncCtx, i.asyncCancel = context.WithCancel(context.Background())
+	}
+	i.asyncPending++
+	return i.asyncCtx
+}
+
+// Queues a finished async call to be settled. Called on the call's goroutine.
+func (i *Isolate) finishAsyncCall(call *asyncCall) {
+	i.asyncMutex.Lock()
+	i.asyncResults = append(i.asyncResults, call)
+	i.asyncMutex.Unlock()
+	select {
+	case i.asyncReady <- struct{}{}:
+	default: // already signaled
+	}
+}
+
+// Cancels the Go contexts of the running async calls; called when the Isolate is disposed.
+func (i *Isolate) cancelAsyncCalls() {
+	i.asyncMutex.Lock()
+	defer i.asyncMutex.Unlock()
+	if i.asyncCancel != nil {
+		i.asyncCancel()
+	}
+}
+
+// Converts a JavaScript value to Go for an AsyncFunctionCallback.
+func goValueOf(v *Value) (interface{}, error) {
+	switch {
+	case v.IsNullOrUndefined():
+		return nil, nil
+	case v.IsBoolean():
+		return v.Boolean(), nil
+	case v.IsNumber():
+		return v.Number(), nil
+	case v.IsBigInt():
+		return v.BigInt(), nil
+	case v.IsString():
+		return v.String(), nil
+	case v.IsDate():
+		return v.Date(), nil
+	case v.IsFunction() || v.IsSymbol():
+		return nil, errors.New("TypeError: functions and symbols can't be passed to an async Go function")
+	}
+	str, err := JSONStringify(v.ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err = json.Unmarshal([]byte(str), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Converts the result of an AsyncFunctionCallback to JavaScript.
+func asyncResultValue(ctx *Context, result interface{}) (*Value, error) {
+	if result == nil {
+		return Undefined(ctx.iso), nil
+	}
+	val, err := ctx.NewValue(result)
+	if errors.Is(err, ErrUnsupportedValueType) {
+		data, jsonErr := json.Marshal(result)
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+		return JSONParse(ctx, string(data))
+	}
+	return val, err
+}
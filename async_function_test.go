@@ -0,0 +1,175 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestAsyncFunctionTemplate(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	global := v8.NewObjectTemplate(iso)
+	add := v8.NewAsyncFunctionTemplate(iso, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		a, _ := args[0].(float64)
+		b, _ := args[1].(float64)
+		time.Sleep(10 * time.Millisecond)
+		return a + b, nil
+	})
+	fatalIf(t, global.Set("add", add))
+	fail := v8.NewAsyncFunctionTemplate(iso, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		return nil, errors.New("TypeError: bad argument")
+	})
+	fatalIf(t, global.Set("fail", fail))
+	ctx := v8.NewContext(iso, global)
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`
+		var results = [];
+		add(1, 2).then(sum => add(sum, 3)).then(sum => results.push(sum));
+		fail().catch(e => results.push(e instanceof TypeError && e.message));
+		add(1, 2)`, "async.js")
+	fatalIf(t, err)
+	if !val.IsPromise() {
+		t.Fatalf("expected a Promise, got %v", val)
+	}
+
+	fatalIf(t, iso.WaitAsyncCalls(context.Background()))
+	results, err := ctx.RunScript("results.sort().join()", "results.js")
+	fatalIf(t, err)
+	if results.String() != "6,bad argument" {
+		t.Errorf("unexpected results %q", results.String())
+	}
+	prom, _ := val.AsPromise()
+	if prom.State() != v8.Fulfilled || prom.Result().Number() != 3 {
+		t.Errorf("unexpected Promise state %v, result %v", prom.State(), prom.Result())
+	}
+	if iso.SettleAsyncCalls() != 0 {
+		t.Error("expected no async calls to be running")
+	}
+}
+
+func TestAsyncFunctionFinishesWhileWaiting(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	release := make(chan struct{})
+	fn := v8.NewAsyncFunctionTemplate(iso, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		<-release
+		return "done", nil
+	})
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	fatalIf(t, ctx.Global().Set("fn", fn.GetFunction(ctx)))
+
+	val, err := ctx.RunScript("fn()", "wait.js")
+	fatalIf(t, err)
+	if iso.SettleAsyncCalls() != 1 {
+		t.Fatal("expected the async call to still be running")
+	}
+
+	// The callback finishes only after WaitAsyncCalls has started waiting:
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	fatalIf(t, iso.WaitAsyncCalls(waitCtx))
+
+	prom, _ := val.AsPromise()
+	if prom.State() != v8.Fulfilled || prom.Result().String() != "done" {
+		t.Errorf("unexpected Promise state %v, result %v", prom.State(), prom.Result())
+	}
+}
+
+func TestAsyncFunctionConversions(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	var received []interface{}
+	fn := v8.NewAsyncFunctionTemplate(iso, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		received = args
+		return struct {
+			Names []string `json:"names"`
+		}{[]string{"a", "b"}}, nil
+	})
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	fatalIf(t, ctx.Global().Set("fn", fn.GetFunction(ctx)))
+
+	val, err := ctx.RunScript(`fn(null, true, 1.5, 12n, "str", new Date(0), {a: [1, "x"]})`, "conv.js")
+	fatalIf(t, err)
+	fatalIf(t, iso.WaitAsyncCalls(context.Background()))
+
+	expected := []interface{}{nil, true, 1.5, big.NewInt(12), "str", time.Unix(0, 0),
+		map[string]interface{}{"a": []interface{}{1.0, "x"}}}
+	if len(received) != len(expected) {
+		t.Fatalf("expected %d args, got %d", len(expected), len(received))
+	}
+	for i, arg := range received {
+		if tm, ok := arg.(time.Time); ok {
+			if !tm.Equal(expected[i].(time.Time)) {
+				t.Errorf("arg %d: expected %v, got %v", i, expected[i], tm)
+			}
+		} else if n, ok := arg.(*big.Int); ok {
+			if n.Cmp(expected[i].(*big.Int)) != 0 {
+				t.Errorf("arg %d: expected %v, got %v", i, expected[i], n)
+			}
+		} else if !reflect.DeepEqual(arg, expected[i]) {
+			t.Errorf("arg %d: expected %#v, got %#v", i, expected[i], arg)
+		}
+	}
+
+	prom, _ := val.AsPromise()
+	result, err := v8.JSONStringify(ctx, prom.Result())
+	fatalIf(t, err)
+	if result != `{"names":["a","b"]}` {
+		t.Errorf("unexpected result %s", result)
+	}
+
+	// Functions can't be passed, so the Promise is rejected without calling the callback:
+	val, err = ctx.RunScript("fn(() => 1)", "conv.js")
+	fatalIf(t, err)
+	prom, _ = val.AsPromise()
+	if prom.State() != v8.Rejected {
+		t.Errorf("expected the Promise to be rejected, got %v", prom.State())
+	}
+}
+
+func TestAsyncFunctionCanceledOnDispose(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	canceled := make(chan error)
+	fn := v8.NewAsyncFunctionTemplate(iso, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return nil, ctx.Err()
+	})
+	ctx := v8.NewContext(iso)
+	fatalIf(t, ctx.Global().Set("fn", fn.GetFunction(ctx)))
+	_, err := ctx.RunScript("fn()", "cancel.js")
+	fatalIf(t, err)
+	ctx.Close()
+	iso.Dispose()
+
+	select {
+	case err := <-canceled:
+		if err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected the async call to be canceled when the Isolate was disposed")
+	}
+}
@@ -9,3 +9,10 @@ import "time"
 func timeUnixMicro(usec int64) time.Time {
 	return time.Unix(0, usec*1000)
 }
+
+// Backport time.UnixMilli from go 1.17 - https://pkg.go.dev/time#UnixMilli
+// timeUnixMilli returns the local Time corresponding to the given Unix time,
+// msec milliseconds since January 1, 1970 UTC.
+func timeUnixMilli(msec int64) time.Time {
+	return time.Unix(msec/1e3, (msec%1e3)*1e6)
+}
@@ -0,0 +1,194 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CodeCacheStore is persistent storage for a CodeCache. Its methods may be called
+// concurrently by different Isolates.
+type CodeCacheStore interface {
+	// Get returns the data stored under the key, or nil if there is none.
+	Get(key string) ([]byte, error)
+	// Put stores data under the key, replacing any existing data.
+	Put(key string, data []byte) error
+	// Delete removes the data stored under the key, if any.
+	Delete(key string) error
+}
+
+// CodeCacheStats are the statistics of a CodeCache.
+type CodeCacheStats struct {
+	Hits     int // Number of compilations that found a cache
+	Misses   int // Number of compilations that found no cache
+	Rejected int // Number of caches found that V8 rejected, and were evicted
+	Saved    int // Number of caches saved
+	Errors   int // Number of errors from the store, which are otherwise ignored
+}
+
+// CodeCache manages the code caches of compiled scripts, so that compiling the same script
+// again, even in a later process, is faster. Set it as IsolateOptions.CodeCache, and
+// Isolate.CompileUnboundScript will use it automatically:
+//   - Before compiling, it looks for a cache whose key is a hash of the source, the origin,
+//     the V8 version and the V8 flags that affect compilation.
+//   - If there's no cache, or V8 rejects it, a new cache is saved after the script's first
+//     Run, so that it includes the functions compiled lazily while running.
+//   - Rejected caches are evicted from the store.
+//
+// Compilations that pass their own CompileOptions.CachedData, or a CompileOptions.Mode other
+// than CompileModeDefault, don't use the CodeCache, so a cache never overrides the Mode.
+// Neither do scripts compiled by Isolate.StartStreamingScript, since their source isn't known
+// until it has been compiled; pass CompileOptions.CachedData for those instead.
+//
+// A CodeCache can be shared by multiple Isolates.
+type CodeCache struct {
+	store CodeCacheStore
+	mutex sync.Mutex
+	stats CodeCacheStats
+}
+
+// NewCodeCache creates a CodeCache that keeps caches in a store.
+func NewCodeCache(store CodeCacheStore) *CodeCache {
+	return &CodeCache{store: store}
+}
+
+// Stats returns the hit/miss statistics of the CodeCache.
+func (c *CodeCache) Stats() CodeCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.stats
+}
+
+// Returns the key of a script's cache.
+func (c *CodeCache) key(source, origin string, scriptOrigin *ScriptOrigin) string {
+	h := sha256.New()
+	writeString := func(str string) {
+		var length [8]byte
+		binary.LittleEndian.PutUint64(length[:], uint64(len(str)))
+		h.Write(length[:])
+		h.Write([]byte(str))
+	}
+	writeString(Version())
+	var tag [4]byte
+	// The version tag also covers the V8 flags that affect code caches:
+	binary.LittleEndian.PutUint32(tag[:], uint32(C.CachedDataVersionTag()))
+	h.Write(tag[:])
+	writeString(origin)
+	if scriptOrigin != nil {
+		var offsets [16]byte
+		binary.LittleEndian.PutUint64(offsets[0:], uint64(scriptOrigin.LineOffset))
+		binary.LittleEndian.PutUint64(offsets[8:], uint64(scriptOrigin.ColumnOffset))
+		writeString(scriptOrigin.ResourceName)
+		h.Write(offsets[:])
+	}
+	writeString(source)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Returns the cache stored under the key, or nil.
+func (c *CodeCache) load(key string) *CompilerCachedData {
+	data, err := c.store.Get(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err != nil {
+		c.stats.Errors++
+	}
+	if len(data) == 0 {
+		c.stats.Misses++
+		return nil
+	}
+	c.stats.Hits++
+	return &CompilerCachedData{Bytes: data}
+}
+
+// Saves a cache under the key.
+func (c *CodeCache) save(key string, data *CompilerCachedData) {
+	if data == nil || len(data.Bytes) == 0 {
+		return
+	}
+	err := c.store.Put(key, data.Bytes)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err != nil {
+		c.stats.Errors++
+	} else {
+		c.stats.Saved++
+	}
+}
+
+// Removes a rejected cache.
+func (c *CodeCache) evict(key string) {
+	err := c.store.Delete(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stats.Rejected++
+	if err != nil {
+		c.stats.Errors++
+	}
+}
+
+// DirCodeCacheStore is a CodeCacheStore that keeps each cache in a file in a directory.
+type DirCodeCacheStore struct {
+	dir string
+}
+
+// NewDirCodeCacheStore creates a DirCodeCacheStore, creating the directory if necessary.
+func NewDirCodeCacheStore(dir string) (*DirCodeCacheStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DirCodeCacheStore{dir: dir}, nil
+}
+
+func (s *DirCodeCacheStore) path(key string) string {
+	return filepath.Join(s.dir, key+".codecache")
+}
+
+// Get returns the contents of the key's file, or nil if it doesn't exist.
+func (s *DirCodeCacheStore) Get(key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Put writes the data to the key's file. The file is replaced atomically, so concurrent
+// readers never see a partial cache.
+func (s *DirCodeCacheStore) Put(key string, data []byte) error {
+	tmp, err := ioutil.TempFile(s.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Delete removes the key's file, if it exists.
+func (s *DirCodeCacheStore) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
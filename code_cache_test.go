@@ -0,0 +1,96 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestCodeCache(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	store, err := v8.NewDirCodeCacheStore(dir)
+	fatalIf(t, err)
+	cache := v8.NewCodeCache(store)
+
+	const source = "function fib(n) { return n < 2 ? n : fib(n - 1) + fib(n - 2) }; fib(10)"
+	compileAndRun := func() {
+		iso := v8.NewIsolateWithOptions(v8.IsolateOptions{CodeCache: cache})
+		defer iso.Dispose()
+		ctx := v8.NewContext(iso)
+		defer ctx.Close()
+		script, err := iso.CompileUnboundScript(source, "fib.js", v8.CompileOptions{})
+		fatalIf(t, err)
+		val, err := script.Run(ctx)
+		fatalIf(t, err)
+		if val.Int32() != 55 {
+			t.Errorf("expected 55, got %v", val)
+		}
+	}
+
+	compileAndRun()
+	if stats := cache.Stats(); stats != (v8.CodeCacheStats{Misses: 1, Saved: 1}) {
+		t.Errorf("unexpected stats after first compile: %+v", stats)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.codecache"))
+	if len(files) != 1 {
+		t.Fatalf("expected one cache file, got %v", files)
+	}
+
+	compileAndRun()
+	if stats := cache.Stats(); stats != (v8.CodeCacheStats{Hits: 1, Misses: 1, Saved: 1}) {
+		t.Errorf("unexpected stats after second compile: %+v", stats)
+	}
+
+	// A corrupted cache is rejected, evicted and replaced:
+	fatalIf(t, ioutil.WriteFile(files[0], []byte("this is not a code cache"), 0644))
+	compileAndRun()
+	if stats := cache.Stats(); stats != (v8.CodeCacheStats{Hits: 2, Misses: 1, Rejected: 1, Saved: 2}) {
+		t.Errorf("unexpected stats after corrupt cache: %+v", stats)
+	}
+	if data, _ := ioutil.ReadFile(files[0]); string(data) == "this is not a code cache" {
+		t.Error("expected corrupted cache to be replaced")
+	}
+
+	// A script that throws on its first run isn't cached until it runs successfully:
+	iso := v8.NewIsolateWithOptions(v8.IsolateOptions{CodeCache: cache})
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	throws, err := iso.CompileUnboundScript("if (!globalThis.ready) throw new Error('not yet'); 1", "throws.js", v8.CompileOptions{})
+	fatalIf(t, err)
+	if _, err = throws.Run(ctx); err == nil {
+		t.Error("expected the script to throw")
+	}
+	if stats := cache.Stats(); stats.Saved != 2 {
+		t.Errorf("expected no cache to be saved after a failed run: %+v", stats)
+	}
+	_, err = ctx.RunScript("globalThis.ready = true", "ready.js")
+	fatalIf(t, err)
+	_, err = throws.Run(ctx)
+	fatalIf(t, err)
+	if stats := cache.Stats(); stats.Saved != 3 {
+		t.Errorf("expected a cache to be saved after a successful run: %+v", stats)
+	}
+
+	// A different origin is a different script:
+	_, err = iso.CompileUnboundScript(source, "other.js", v8.CompileOptions{})
+	fatalIf(t, err)
+	if stats := cache.Stats(); stats.Misses != 3 {
+		t.Errorf("expected a miss for a different origin: %+v", stats)
+	}
+
+	// A non-default Mode bypasses the cache, rather than being overridden by it:
+	before := cache.Stats()
+	_, err = iso.CompileUnboundScript(source, "fib.js", v8.CompileOptions{Mode: v8.CompileModeEager})
+	fatalIf(t, err)
+	if stats := cache.Stats(); stats != before {
+		t.Errorf("expected the cache not to be used with CompileModeEager: %+v", stats)
+	}
+}
@@ -17,13 +17,59 @@ namespace v8go {
     context_->SetAlignedPointerInEmbedderData(1, this);
   }
 
+  void V8GoContext::captureIntrinsics() {
+    static const char* const kIntrinsicNames[kNumObjectIntrinsics] = {
+      "preventExtensions", "isExtensible", "isSealed", "isFrozen"};
+    Local<Context> context = _ptr.Get(iso);
+    Context::Scope scope(context);
+    // A global template can replace `Object`, so check everything. Any intrinsic that can't be
+    // found is left empty, which makes ObjectCallIntrinsic fail instead of creating the context.
+    TryCatch tryCatch(iso);
+    Local<Value> ctor;
+    if (!context->Global()->Get(context, String::NewFromUtf8Literal(iso, "Object")).ToLocal(&ctor)
+        || !ctor->IsObject()) {
+      return;
+    }
+    for (int i = 0; i < kNumObjectIntrinsics; i++) {
+      Local<Value> fn;
+      Local<String> name = String::NewFromUtf8(iso, kIntrinsicNames[i]).ToLocalChecked();
+      if (ctor.As<Object>()->Get(context, name).ToLocal(&fn) && fn->IsFunction()) {
+        _intrinsics[i].Reset(iso, fn.As<Function>());
+      }
+    }
+  }
+
   V8GoContext::~V8GoContext() {
+    for (GoRef* ref : _goRefs) {
+      releaseGoRef(ref);
+    }
+    for (V8GoUnboundScript* script : _unboundScripts) {
+      delete script;
+    }
     _ptr.Reset(); // (~Persistent does not do this due to NonCopyable traits)
   #ifdef CTX_LOG_VALUES
     fprintf(stderr, "*** m_ctx created %zu values, max table size %zu\n", _nValues, _maxValues);
   #endif
   }
 
+  void V8GoContext::addGoRef(Local<Object> obj, GoRefKind kind, int id) {
+    auto ref = new GoRef{this, kind, id, Global<Object>(iso, obj)};
+    ref->obj.SetWeak(ref, &objectCollected, WeakCallbackType::kParameter);
+    _goRefs.insert(ref);
+  }
+
+  void V8GoContext::objectCollected(const WeakCallbackInfo<GoRef>& info) {
+    GoRef* ref = info.GetParameter();
+    ref->ctx->_goRefs.erase(ref);
+    releaseGoRef(ref);
+  }
+
+  void V8GoContext::releaseGoRef(GoRef* ref) {
+    goReleaseRef(ref->ctx->goRef, ref->kind, ref->id);
+    ref->obj.Reset();
+    delete ref;
+  }
+
   V8GoContext* V8GoContext::fromContext(Local<Context> ctx) {
     return reinterpret_cast<V8GoContext*>(ctx->GetAlignedPointerFromEmbedderData(1));
   }
@@ -77,8 +123,15 @@ namespace v8go {
   }
 
   V8GoUnboundScript* V8GoContext::newUnboundScript(Local<UnboundScript> script) {
-    _unboundScripts.emplace_back(iso, std::move(script));
-    return &_unboundScripts.back();
+    auto us = new V8GoUnboundScript(iso, script);
+    _unboundScripts.insert(us);
+    return us;
+  }
+
+  void V8GoContext::freeUnboundScript(V8GoUnboundScript* us) {
+    if (_unboundScripts.erase(us)) {
+      delete us;
+    }
   }
 
 }
@@ -100,7 +153,9 @@ ContextPtr NewContext(IsolatePtr iso,
 
   Local<Context> local_ctx = Context::New(iso, nullptr, global_template);
 
-  return new V8GoContext(iso, local_ctx, goRef);
+  auto ctx = new V8GoContext(iso, local_ctx, goRef);
+  ctx->captureIntrinsics();
+  return ctx;
 }
 
 void ContextFree(ContextPtr ctx) {
@@ -114,11 +169,47 @@ ValueRef ContextGlobal(ContextPtr ctx) {
 
 RtnValue RunScript(ContextPtr ctx, const char* source, int sourceLen,
                    const char* origin, int originLen) {
+  ScriptOriginParams params = {};
+  params.resourceName = origin;
+  params.resourceNameLen = originLen;
+  return RunScriptWithOrigin(ctx, source, sourceLen, params);
+}
+
+RtnValue RunScriptWithOrigin(ContextPtr ctx, const char* source, int sourceLen,
+                             ScriptOriginParams origin) {
   WithContext _with(ctx);
   auto iso = ctx->iso;
 
   RtnValue rtn = {};
 
+  MaybeLocal<String> maybeSrc =
+      String::NewFromUtf8(iso, source, NewStringType::kNormal, sourceLen);
+  Local<String> src;
+  if (!maybeSrc.ToLocal(&src)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+
+  ScriptOrigin script_origin = NewScriptOrigin(iso, origin, String::Empty(iso));
+  Local<Script> script;
+  if (!Script::Compile(_with.local_ctx, src, &script_origin).ToLocal(&script)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  return _with.returnValue(script->Run(_with.local_ctx));
+}
+
+RtnCompiledFunction ContextCompileFunction(ContextPtr ctx,
+                                           const char* source, int sourceLen,
+                                           const char* origin, int originLen,
+                                           int paramCount, ValuePtr params[],
+                                           int extensionCount, ValuePtr extensions[],
+                                           CompileOptions opts) {
+  WithContext _with(ctx);
+  auto iso = ctx->iso;
+
+  RtnCompiledFunction rtn = {};
+
   MaybeLocal<String> maybeSrc =
       String::NewFromUtf8(iso, source, NewStringType::kNormal, sourceLen);
   MaybeLocal<String> maybeOgn =
@@ -129,13 +220,37 @@ RtnValue RunScript(ContextPtr ctx, const char* source, int sourceLen,
     return rtn;
   }
 
-  ScriptOrigin script_origin(ogn);
-  Local<Script> script;
-  if (!Script::Compile(_with.local_ctx, src, &script_origin).ToLocal(&script)) {
+  Local<String> paramNames[paramCount];
+  for (int i = 0; i < paramCount; i++) {
+    paramNames[i] = Deref(params[i]).As<String>();
+  }
+  Local<Object> extensionObjs[extensionCount];
+  for (int i = 0; i < extensionCount; i++) {
+    extensionObjs[i] = Deref(extensions[i]).As<Object>();
+  }
+
+  ScriptCompiler::CompileOptions option =
+      static_cast<ScriptCompiler::CompileOptions>(opts.compileOption);
+  ScriptCompiler::CachedData* cached_data = nullptr;
+  if (opts.cachedData.data) {
+    cached_data = new ScriptCompiler::CachedData(opts.cachedData.data,
+                                                 opts.cachedData.length);
+  }
+
+  ScriptOrigin script_origin = NewScriptOrigin(iso, opts.origin, ogn);
+  ScriptCompiler::Source src_obj(src, script_origin, cached_data);
+
+  Local<Function> fn;
+  if (!ScriptCompiler::CompileFunction(_with.local_ctx, &src_obj, paramCount, paramNames,
+                                       extensionCount, extensionObjs, option).ToLocal(&fn)) {
     rtn.error = _with.exceptionError();
     return rtn;
   }
-  return _with.returnValue(script->Run(_with.local_ctx));
+  if (cached_data) {
+    rtn.cachedDataRejected = cached_data->rejected;
+  }
+  rtn.value = ctx->addValue(fn);
+  return rtn;
 }
 
 /********** JSON **********/
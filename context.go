@@ -19,6 +19,9 @@ type Context struct {
 	ptr        C.ContextPtr // Pointer to C++ V8GoContext object
 	iso        *Isolate     // The Isolate this Context belongs to
 	selfHandle cgo.Handle   // Opaque handle pointing to the Context itself
+
+	proxyHandlers map[int32]ProxyHandler // Go handlers of Proxies created by NewProxyFunc
+	iterators     map[int32]*goIterator  // Go sources of iterables created by NewIterable
 }
 
 type contextOptions struct {
@@ -71,6 +74,7 @@ func (c *Context) Isolate() *Isolate {
 // reference for the script and used in the stack trace if there is an error.
 // error will be of type `JSError` if not nil.
 func (c *Context) RunScript(source string, origin string) (*Value, error) {
+	c.iso.checkOwner()
 	cSource := C.CString(source)
 	cOrigin := C.CString(origin)
 	defer C.free(unsafe.Pointer(cSource))
@@ -80,6 +84,74 @@ func (c *Context) RunScript(source string, origin string) (*Value, error) {
 	return valueResult(c, rtn)
 }
 
+// RunScriptWithOrigin is like RunScript, but takes a ScriptOrigin that can describe where the
+// source is embedded in a larger file, so that error locations and stack traces are correct.
+func (c *Context) RunScriptWithOrigin(source string, origin ScriptOrigin) (*Value, error) {
+	c.iso.checkOwner()
+	params, free, err := origin.toC()
+	if err != nil {
+		return nil, err
+	}
+	defer free()
+	cSource := C.CString(source)
+	defer C.free(unsafe.Pointer(cSource))
+
+	rtn := C.RunScriptWithOrigin(c.ptr, cSource, C.int(len(source)), params)
+	return valueResult(c, rtn)
+}
+
+// CompileFunction compiles a function whose body is the source code and whose parameters have
+// the given names, like `new Function(...params, source)` but without going through a string
+// wrapper, so error locations refer to the source itself. The properties of the extension
+// objects, if any, are in scope in the function body, like variables of enclosing scopes.
+// If the options contain CachedData (from Function.CreateCodeCache), compilation will use it.
+// error will be of type `JSError` if not nil.
+func (c *Context) CompileFunction(source, origin string, params []string, extensions []*Object, opts CompileOptions) (*Function, error) {
+	c.iso.checkOwner()
+	cParams := make([]C.ValuePtr, len(params))
+	for i, param := range params {
+		paramVal, err := c.NewValue(param)
+		if err != nil {
+			return nil, err
+		}
+		cParams[i] = paramVal.valuePtr()
+	}
+	cExtensions := make([]C.ValuePtr, len(extensions))
+	for i, ext := range extensions {
+		cExtensions[i] = ext.valuePtr()
+	}
+	var paramPtr, extPtr *C.ValuePtr
+	if len(cParams) > 0 {
+		paramPtr = &cParams[0]
+	}
+	if len(cExtensions) > 0 {
+		extPtr = &cExtensions[0]
+	}
+
+	cOptions, free, err := opts.toC()
+	if err != nil {
+		return nil, err
+	}
+	defer free()
+	cSource := C.CString(source)
+	cOrigin := C.CString(origin)
+	defer C.free(unsafe.Pointer(cSource))
+	defer C.free(unsafe.Pointer(cOrigin))
+
+	rtn := C.ContextCompileFunction(c.ptr, cSource, C.int(len(source)), cOrigin, C.int(len(origin)),
+		C.int(len(cParams)), paramPtr, C.int(len(cExtensions)), extPtr, cOptions)
+	runtime.KeepAlive(cParams)
+	runtime.KeepAlive(cExtensions)
+	runtime.KeepAlive(opts.CachedData)
+	if rtn.error.msg != nil {
+		return nil, newJSError(rtn.error)
+	}
+	if opts.CachedData != nil {
+		opts.CachedData.Rejected = rtn.cachedDataRejected == 1
+	}
+	return &Function{Value: &Value{rtn.value, c}, compiled: true}, nil
+}
+
 // Global returns the global proxy object.
 // Global proxy object is a thin wrapper whose prototype points to actual
 // context's global object with the properties like Object, etc. This is
@@ -88,6 +160,7 @@ func (c *Context) RunScript(source string, origin string) (*Value, error) {
 // would break the VM — V8 expects only global object as a prototype of
 // global proxy object.
 func (c *Context) Global() *Object {
+	c.iso.checkOwner()
 	valPtr := C.ContextGlobal(c.ptr)
 	v := &Value{valPtr, c}
 	return &Object{v}
@@ -103,11 +176,43 @@ func (c *Context) PerformMicrotaskCheckpoint() {
 // You must call this yourself: the Go garbage collector will not free an unused open Context!
 // Access to any values associated with the context after calling Close may panic.
 func (c *Context) Close() {
+	c.iso.checkOwner()
 	C.ContextFree(c.ptr)
 	c.selfHandle.Delete()
 	c.ptr = nil
 }
 
+// Kinds of Go objects whose lifetime is tied to a JavaScript object by addGoRef.
+type goRefKind int
+
+const ( // This MUST be kept in sync with `GoRefKind` in v8go.h!
+	functionCallbackRef goRefKind = iota
+	proxyHandlerRef
+	iteratorRef
+)
+
+// Arranges for goReleaseRef to be called when obj is garbage-collected, or the Context is
+// closed, so the Go object with this kind and ID can be released.
+func (c *Context) addGoRef(obj *Object, kind goRefKind, id int32) {
+	C.ObjectAddGoRef(obj.valuePtr(), C.GoRefKind(kind), C.int(id))
+}
+
+//export goReleaseRef
+func goReleaseRef(ctxHandle C.uintptr_t, kind C.GoRefKind, id C.int) {
+	switch goRefKind(kind) {
+	case functionCallbackRef:
+		goFunctionCallbacks.Delete(int32(id))
+	case proxyHandlerRef:
+		delete(contextFromHandle(ctxHandle).proxyHandlers, int32(id))
+	case iteratorRef:
+		ctx := contextFromHandle(ctxHandle)
+		if it := ctx.iterators[int32(id)]; it != nil {
+			delete(ctx.iterators, int32(id))
+			it.stop()
+		}
+	}
+}
+
 func valueResult(ctx *Context, rtn C.RtnValue) (*Value, error) {
 	if rtn.error.msg != nil {
 		return nil, newJSError(rtn.error)
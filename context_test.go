@@ -7,6 +7,7 @@ package v8go_test
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	v8 "github.com/couchbasedeps/v8go"
@@ -183,3 +184,125 @@ func ExampleContext_globalTemplate() {
 	// Output:
 	// v1.0.0
 }
+
+func TestContextCompileFunction(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	ext := ctx.NewObject()
+	ext.Set("scale", 10)
+	source := "// add and scale\nreturn (a + b) * scale;"
+	fn, err := ctx.CompileFunction(source, "snippet.js", []string{"a", "b"}, []*v8.Object{ext}, v8.CompileOptions{})
+	fatalIf(t, err)
+	one, _ := v8.NewValue(iso, int32(1))
+	two, _ := v8.NewValue(iso, int32(2))
+	result, err := fn.Call(v8.Undefined(iso), one, two)
+	fatalIf(t, err)
+	if result.Int32() != 30 {
+		t.Errorf("expected 30, got %v", result)
+	}
+	if n, err := fn.Length(); err != nil || n != 2 {
+		t.Errorf("expected length 2, got %d, %v", n, err)
+	}
+
+	// The body can't escape the function wrapper:
+	if _, err = ctx.CompileFunction("}); (function() {", "escape.js", nil, nil, v8.CompileOptions{}); err == nil {
+		t.Error("expected syntax error escaping the function body")
+	}
+
+	// Error locations refer to the source itself:
+	fn, err = ctx.CompileFunction("\nthrow new Error('oops');", "thrower.js", nil, nil, v8.CompileOptions{})
+	fatalIf(t, err)
+	_, err = fn.Call(v8.Undefined(iso))
+	if jsErr, ok := err.(*v8.JSError); !ok || !strings.HasPrefix(jsErr.Location, "thrower.js:2:") {
+		t.Errorf("expected error on line 2 of thrower.js, got %#v", err)
+	}
+
+	// Code cache:
+	fn, err = ctx.CompileFunction("return x * 2", "double.js", []string{"x"}, nil, v8.CompileOptions{})
+	fatalIf(t, err)
+	fn.Call(v8.Undefined(iso), two)
+	cache := fn.CreateCodeCache()
+	if cache == nil || len(cache.Bytes) == 0 {
+		t.Fatal("expected a code cache")
+	}
+
+	iso2 := v8.NewIsolate()
+	defer iso2.Dispose()
+	ctx2 := v8.NewContext(iso2)
+	defer ctx2.Close()
+	fn, err = ctx2.CompileFunction("return x * 2", "double.js", []string{"x"}, nil, v8.CompileOptions{CachedData: cache})
+	fatalIf(t, err)
+	if cache.Rejected {
+		t.Error("expected code cache to be accepted")
+	}
+	two2, _ := v8.NewValue(iso2, int32(2))
+	if result, err = fn.Call(v8.Undefined(iso2), two2); err != nil || result.Int32() != 4 {
+		t.Errorf("expected 4, got %v, %v", result, err)
+	}
+
+	// Only functions returned by CompileFunction can be cached:
+	val, err := ctx.RunScript("function triple(x) { return x * 3 }; triple", "triple.js")
+	fatalIf(t, err)
+	fn, err = val.AsFunction()
+	fatalIf(t, err)
+	if fn.CreateCodeCache() != nil {
+		t.Error("expected no code cache for a script-defined function")
+	}
+	val, err = ctx.RunScript("triple.bind(null, 2)", "bound.js")
+	fatalIf(t, err)
+	fn, err = val.AsFunction()
+	fatalIf(t, err)
+	if fn.CreateCodeCache() != nil {
+		t.Error("expected no code cache for a bound function")
+	}
+}
+
+func TestRunScriptWithOrigin(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	tag, _ := v8.NewValue(iso, "inline")
+	origin := v8.ScriptOrigin{
+		ResourceName:       "page.html",
+		LineOffset:         10,
+		ColumnOffset:       4,
+		SourceMapURL:       "page.js.map",
+		HostDefinedOptions: []*v8.Value{tag},
+	}
+	_, err := ctx.RunScriptWithOrigin("function f() {}\nundefinedFunction()", origin)
+	if jsErr, ok := err.(*v8.JSError); !ok || !strings.HasPrefix(jsErr.Location, "page.html:12:") {
+		t.Errorf("expected error on line 12 of page.html, got %#v", err)
+	}
+
+	val, err := ctx.Global().Get("f")
+	fatalIf(t, err)
+	fn, _ := val.AsFunction()
+	got := fn.ScriptOrigin()
+	if got.ResourceName != "page.html" || got.LineOffset != 10 || got.ColumnOffset != 4 || got.SourceMapURL != "page.js.map" {
+		t.Errorf("unexpected function origin %+v", got)
+	}
+
+	if _, err = ctx.RunScriptWithOrigin("1", v8.ScriptOrigin{IsModule: true}); err == nil {
+		t.Error("expected error running a module")
+	}
+	origin.HostDefinedOptions = []*v8.Value{ctx.NewObject().Value}
+	if _, err = ctx.RunScriptWithOrigin("1", origin); err == nil {
+		t.Error("expected error with a non-primitive host-defined option")
+	}
+
+	script, err := iso.CompileUnboundScript("throw new Error('x')", "ignored.js", v8.CompileOptions{
+		Origin: &v8.ScriptOrigin{ResourceName: "template.html", LineOffset: 5},
+	})
+	fatalIf(t, err)
+	_, err = script.Run(ctx)
+	if jsErr, ok := err.(*v8.JSError); !ok || !strings.HasPrefix(jsErr.Location, "template.html:6:") {
+		t.Errorf("expected error on line 6 of template.html, got %#v", err)
+	}
+}
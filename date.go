@@ -0,0 +1,50 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"math"
+	"time"
+)
+
+// Date is a JavaScript `Date` object. JavaScript dates have millisecond precision and a range
+// of ±100,000,000 days around 1970; a Date can also be invalid (`new Date(NaN)`).
+type Date struct {
+	*Object
+}
+
+// NewDate creates a Date representing the given time, truncated to milliseconds.
+// If the time is outside the range of a JavaScript Date, the Date is invalid.
+func NewDate(ctx *Context, t time.Time) (*Date, error) {
+	ms := float64(t.Unix())*1e3 + float64(t.Nanosecond()/1e6)
+	obj, err := objectResult(ctx, C.NewDate(ctx.ptr, C.double(ms)))
+	if err != nil {
+		return nil, err
+	}
+	return &Date{obj}, nil
+}
+
+// UnixMilli returns the Date's time value: the number of milliseconds since January 1, 1970
+// UTC, which is NaN if the Date is invalid.
+func (d *Date) UnixMilli() float64 {
+	return float64(C.DateValueOf(d.valuePtr()))
+}
+
+// IsValid returns false if the Date is invalid, i.e. its time value is NaN.
+func (d *Date) IsValid() bool {
+	return !math.IsNaN(d.UnixMilli())
+}
+
+// Time returns the Date as a Go Time in the local time zone, or the zero Time if the Date
+// is invalid.
+func (d *Date) Time() time.Time {
+	ms := d.UnixMilli()
+	if math.IsNaN(ms) {
+		return time.Time{}
+	}
+	return timeUnixMilli(int64(ms))
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestDate(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	when := time.Date(2022, time.March, 14, 15, 9, 26, 535897932, time.UTC)
+	date, err := v8.NewDate(ctx, when)
+	fatalIf(t, err)
+	if !date.IsDate() || !date.IsValid() {
+		t.Fatal("expected a valid Date")
+	}
+	truncated := when.Truncate(time.Millisecond)
+	if got := date.Time(); !got.Equal(truncated) {
+		t.Errorf("expected %v, got %v", truncated, got)
+	}
+	if ms := date.UnixMilli(); ms != float64(truncated.UnixNano()/1e6) {
+		t.Errorf("unexpected UnixMilli %v", ms)
+	}
+
+	fatalIf(t, ctx.Global().Set("date", date))
+	val, err := ctx.RunScript("date.toISOString()", "")
+	fatalIf(t, err)
+	if s := val.String(); s != "2022-03-14T15:09:26.535Z" {
+		t.Errorf("unexpected ISO string %s", s)
+	}
+
+	// Before 1970, milliseconds still truncate toward the past:
+	early := time.Date(1969, time.December, 31, 23, 59, 59, 999999999, time.UTC)
+	val, err = ctx.NewValue(early)
+	fatalIf(t, err)
+	if got := val.Date(); !got.Equal(early.Truncate(time.Millisecond)) {
+		t.Errorf("unexpected round trip of %v: %v", early, got)
+	}
+
+	val, err = ctx.RunScript("new Date(Date.UTC(2000, 0, 1, 0, 0, 0, 7))", "")
+	fatalIf(t, err)
+	if got := val.Date(); !got.Equal(time.Date(2000, 1, 1, 0, 0, 0, 7e6, time.UTC)) {
+		t.Errorf("unexpected Date from JS: %v", got)
+	}
+	fromJS, err := val.AsDate()
+	fatalIf(t, err)
+	if !fromJS.IsValid() {
+		t.Error("expected valid Date")
+	}
+
+	val, err = ctx.RunScript("new Date(NaN)", "")
+	fatalIf(t, err)
+	invalid, err := val.AsDate()
+	fatalIf(t, err)
+	if invalid.IsValid() || !math.IsNaN(invalid.UnixMilli()) || !invalid.Time().IsZero() {
+		t.Error("expected invalid Date")
+	}
+
+	notDate, err := ctx.NewValue("2022-03-14")
+	fatalIf(t, err)
+	if !notDate.Date().IsZero() {
+		t.Error("expected zero Time for non-Date")
+	}
+	if _, err := notDate.AsDate(); err == nil {
+		t.Error("expected error converting non-Date")
+	}
+}
@@ -8,8 +8,10 @@ package v8go
 // #include "v8go.h"
 import "C"
 import (
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"unsafe"
 )
 
@@ -62,3 +64,148 @@ func (e *JSError) Format(s fmt.State, verb rune) {
 		fmt.Fprintf(s, "%q", e.Message)
 	}
 }
+
+// ErrorKind is the type of a JavaScript Error object created by NewError.
+type ErrorKind int
+
+// This MUST be kept in sync with `ErrorKind` in v8go.h!
+const (
+	GenericError   ErrorKind = iota // `Error`
+	TypeError                       // `TypeError`
+	RangeError                      // `RangeError`
+	SyntaxError                     // `SyntaxError`
+	ReferenceError                  // `ReferenceError`
+)
+
+var errorKindNames = [...]string{"Error", "TypeError", "RangeError", "SyntaxError", "ReferenceError"}
+
+// String returns the name of the error type, e.g. "TypeError".
+func (k ErrorKind) String() string {
+	if k >= 0 && int(k) < len(errorKindNames) {
+		return errorKindNames[k]
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// ErrorOptions are the options for NewErrorWithOptions.
+type ErrorOptions struct {
+	// The `cause` of the error, like `new Error(message, {cause})`; omitted if nil.
+	// May be a *Value or any Go type accepted by Context.NewValue.
+	Cause interface{}
+
+	// Additional properties to set on the error, such as a `code`.
+	Properties map[string]interface{}
+}
+
+// NewError creates a JavaScript Error object of the given kind, like `new TypeError(message)`.
+// Its stack trace is that of the JavaScript running at the time, if any. The result can be
+// thrown from a FunctionCallback with Isolate.ThrowException, or used to reject a Promise.
+func NewError(ctx *Context, kind ErrorKind, message string) (*Object, error) {
+	return NewErrorWithOptions(ctx, kind, message, ErrorOptions{})
+}
+
+// NewErrorWithOptions is like NewError, with an optional cause and extra properties.
+func NewErrorWithOptions(ctx *Context, kind ErrorKind, message string, opts ErrorOptions) (*Object, error) {
+	if ctx == nil {
+		return nil, errors.New("v8go: Context is required")
+	}
+	msg, err := ctx.NewValue(message)
+	if err != nil {
+		return nil, err
+	}
+	var cause C.ValuePtr
+	if opts.Cause != nil {
+		causeVal, err := ctx.NewValue(opts.Cause)
+		if err != nil {
+			return nil, err
+		}
+		cause = causeVal.valuePtr()
+	}
+	obj, err := objectResult(ctx, C.NewError(ctx.ptr, C.int(kind), msg.valuePtr(), cause))
+	if err != nil {
+		return nil, err
+	}
+	for key, val := range opts.Properties {
+		if err = obj.Set(key, val); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// ErrorDetails holds the standard properties of a JavaScript Error object.
+type ErrorDetails struct {
+	Name    string // The error type, e.g. "TypeError"
+	Message string
+	Stack   string // The stack trace, starting with "Name: Message"; may be empty
+	Cause   *Value // The `cause` property, or nil if there is none
+}
+
+// ErrorDetails returns the name, message, stack and cause of a native Error object (one for
+// which IsNativeError is true), such as an exception caught in JavaScript or the reason a
+// Promise was rejected.
+func (v *Value) ErrorDetails() (*ErrorDetails, error) {
+	if !v.IsNativeError() {
+		return nil, errors.New("v8go: value is not an Error")
+	}
+	obj := v.Object()
+	var details ErrorDetails
+	for _, prop := range []struct {
+		name string
+		dst  *string
+	}{{"name", &details.Name}, {"message", &details.Message}, {"stack", &details.Stack}} {
+		val, err := obj.Get(prop.name)
+		if err != nil {
+			return nil, err
+		}
+		if !val.IsUndefined() {
+			*prop.dst = val.String()
+		}
+	}
+	if obj.Has("cause") {
+		cause, err := obj.Get("cause")
+		if err != nil {
+			return nil, err
+		}
+		details.Cause = cause
+	}
+	return &details, nil
+}
+
+// Throws a new Error of the given kind from a FunctionCallback.
+func throwError(ctx *Context, kind ErrorKind, message string) *Value {
+	return ctx.iso.ThrowException(newErrorValue(ctx, kind, message))
+}
+
+// Rethrows an error from a FunctionCallback, restoring the error type of a JSError's message,
+// which is formatted like "TypeError: message".
+func throwJSError(ctx *Context, err error) *Value {
+	return ctx.iso.ThrowException(jsErrorValue(ctx, err))
+}
+
+// Returns a new Error of the given kind, or just the message if the Error can't be created.
+func newErrorValue(ctx *Context, kind ErrorKind, message string) *Value {
+	exc, err := NewError(ctx, kind, message)
+	if err != nil {
+		msg, _ := NewValue(ctx.iso, message)
+		return msg
+	}
+	return exc.Value
+}
+
+// Converts a Go error to a JavaScript Error, restoring the error type as throwJSError does.
+func jsErrorValue(ctx *Context, err error) *Value {
+	kind, message := GenericError, err.Error()
+	if jsErr, ok := err.(*JSError); ok {
+		message = jsErr.Message
+	}
+	if i := strings.Index(message, ": "); i > 0 {
+		for k, name := range errorKindNames {
+			if message[:i] == name {
+				kind, message = ErrorKind(k), message[i+2:]
+				break
+			}
+		}
+	}
+	return newErrorValue(ctx, kind, message)
+}
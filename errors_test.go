@@ -6,6 +6,7 @@ package v8go_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	v8 "github.com/couchbasedeps/v8go"
@@ -117,3 +118,75 @@ func TestJSErrorFormat_forSyntaxError(t *testing.T) {
 		t.Errorf("unexpected verbose error message: %q", msg)
 	}
 }
+
+func TestNewError(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	fail := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		exc, err := v8.NewErrorWithOptions(info.Context(), v8.RangeError, "out of range", v8.ErrorOptions{
+			Cause:      "bad input",
+			Properties: map[string]interface{}{"code": 42},
+		})
+		fatalIf(t, err)
+		return iso.ThrowException(exc.Value)
+	})
+	global := v8.NewObjectTemplate(iso)
+	fatalIf(t, global.Set("fail", fail))
+	ctx := v8.NewContext(iso, global)
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`
+		function caller() { fail(); }
+		let caught;
+		try { caller(); } catch (e) { caught = e; }
+		caught`, "errors.js")
+	fatalIf(t, err)
+	if !val.IsNativeError() {
+		t.Fatal("expected a native error")
+	}
+	details, err := val.ErrorDetails()
+	fatalIf(t, err)
+	if details.Name != "RangeError" || details.Message != "out of range" {
+		t.Errorf("unexpected name/message %q / %q", details.Name, details.Message)
+	}
+	if !strings.HasPrefix(details.Stack, "RangeError: out of range") || !strings.Contains(details.Stack, "caller") {
+		t.Errorf("expected stack to include the JS caller, got %q", details.Stack)
+	}
+	if details.Cause == nil || details.Cause.String() != "bad input" {
+		t.Errorf("unexpected cause %v", details.Cause)
+	}
+
+	check, err := ctx.RunScript(`caught instanceof RangeError && caught.code === 42 &&
+		!Object.keys(caught).includes("cause")`, "")
+	fatalIf(t, err)
+	if !check.Boolean() {
+		t.Error("expected a RangeError with an enumerable code and non-enumerable cause")
+	}
+
+	for kind, name := range map[v8.ErrorKind]string{
+		v8.GenericError:   "Error",
+		v8.TypeError:      "TypeError",
+		v8.RangeError:     "RangeError",
+		v8.SyntaxError:    "SyntaxError",
+		v8.ReferenceError: "ReferenceError",
+	} {
+		obj, err := v8.NewError(ctx, kind, "msg")
+		fatalIf(t, err)
+		details, err := obj.ErrorDetails()
+		fatalIf(t, err)
+		if details.Name != name || kind.String() != name {
+			t.Errorf("expected %s, got %s / %s", name, details.Name, kind)
+		}
+		if details.Cause != nil {
+			t.Errorf("expected no cause, got %v", details.Cause)
+		}
+	}
+
+	notError, err := ctx.RunScript("({name: 'Error', message: 'fake'})", "")
+	fatalIf(t, err)
+	if _, err := notError.ErrorDetails(); err == nil {
+		t.Error("expected error for a non-Error object")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Executor owns an Isolate and runs every call on it on one dedicated goroutine, locked to an
+// OS thread, so that the Isolate and its Contexts and Values can be used safely from any
+// goroutine. Instead of calling the Isolate directly, pass a function to Run; calls from
+// different goroutines are serialized, like messages to an actor.
+//
+// Values returned from one call can be kept and used in later calls, on any goroutine, but
+// only inside functions passed to Run.
+type Executor struct {
+	iso       *Isolate
+	goid      int64         // ID of the goroutine that runs the calls (atomic)
+	calls     chan func()   // Calls waiting to run
+	quit      chan struct{} // Closed by Close
+	stopped   chan struct{} // Closed when the Isolate has been disposed
+	closeOnce sync.Once
+}
+
+var errExecutorClosed = errors.New("v8go: Executor is closed")
+
+// NewExecutor creates an Executor with a new Isolate.
+func NewExecutor(opts IsolateOptions) *Executor {
+	e := &Executor{
+		iso:     NewIsolateWithOptions(opts),
+		calls:   make(chan func()),
+		quit:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	ready := make(chan struct{})
+	go e.run(ready)
+	<-ready
+	return e
+}
+
+func (e *Executor) run(ready chan struct{}) {
+	// The Isolate stays locked to this goroutine's OS thread, so calls don't pay for locking it:
+	atomic.StoreInt64(&e.goid, goroutineID())
+	e.iso.Lock()
+	if ready != nil {
+		close(ready)
+	}
+	exited := true
+	defer func() {
+		if exited {
+			// A call ended this goroutine with runtime.Goexit; release the Isolate, and carry
+			// on with a new goroutine:
+			e.iso.lockDepth = 1
+			e.iso.Unlock()
+			go e.run(nil)
+		}
+	}()
+	for {
+		select {
+		case call := <-e.calls:
+			call()
+		case <-e.quit:
+			e.iso.Dispose()
+			close(e.stopped)
+			exited = false
+			return
+		}
+	}
+}
+
+// Isolate returns the Executor's Isolate. It may only be used inside functions passed to Run.
+// This isn't enforced, except in builds with the `ownercheck` tag, where using it elsewhere
+// panics since the Executor's goroutine holds the Isolate's lock.
+func (e *Executor) Isolate() *Isolate {
+	return e.iso
+}
+
+// Run calls fn with the Isolate on the Executor's goroutine, waiting for it to return, and
+// returns its error. Calls from other goroutines wait until it returns.
+// If fn panics, Run panics with the same value on the calling goroutine; if fn calls
+// runtime.Goexit, as testing.T's FailNow does, Run calls it too.
+//
+// It's safe to call Run from inside fn, or from a callback called by JavaScript; the nested
+// function is called immediately.
+func (e *Executor) Run(fn func(iso *Isolate) error) error {
+	if goroutineID() == atomic.LoadInt64(&e.goid) {
+		return fn(e.iso)
+	}
+	var err error
+	var panicked interface{}
+	goexited := false
+	done := make(chan struct{})
+	call := func() {
+		returned := false
+		defer func() {
+			if !returned {
+				panicked = recover()
+				goexited = panicked == nil
+			}
+			close(done)
+		}()
+		err = fn(e.iso)
+		returned = true
+	}
+	select {
+	case e.calls <- call:
+	case <-e.quit:
+		return errExecutorClosed
+	}
+	<-done
+	if goexited {
+		runtime.Goexit()
+	} else if panicked != nil {
+		panic(panicked)
+	}
+	return err
+}
+
+// Close disposes the Isolate, after the call in progress, if any, returns. Afterwards Run
+// returns an error. Calling Close again does nothing.
+func (e *Executor) Close() {
+	e.closeOnce.Do(func() {
+		close(e.quit)
+	})
+	if goroutineID() != atomic.LoadInt64(&e.goid) {
+		<-e.stopped
+	}
+}
+
+// Returns the ID of the current goroutine, which Go doesn't otherwise expose, from the first
+// line of its stack trace: "goroutine 123 [running]:".
+func goroutineID() int64 {
+	var buf [64]byte
+	line := buf[:runtime.Stack(buf[:], false)]
+	line = bytes.TrimPrefix(line, []byte("goroutine "))
+	if i := bytes.IndexByte(line, ' '); i >= 0 {
+		line = line[:i]
+	}
+	id, _ := strconv.ParseInt(string(line), 10, 64)
+	return id
+}
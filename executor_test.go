@@ -0,0 +1,172 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestExecutorConcurrentCalls(t *testing.T) {
+	t.Parallel()
+
+	e := v8.NewExecutor(v8.IsolateOptions{})
+	defer e.Close()
+
+	var ctx *v8.Context
+	var counter *v8.Object
+	err := e.Run(func(iso *v8.Isolate) error {
+		ctx = v8.NewContext(iso)
+		val, err := ctx.RunScript("({count: 0})", "counter.js")
+		if err == nil {
+			counter, err = val.AsObject()
+		}
+		return err
+	})
+	fatalIf(t, err)
+
+	// Values created in one call are used in calls from many goroutines:
+	const n = 20
+	var wg sync.WaitGroup
+	results := make([]string, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := e.Run(func(iso *v8.Isolate) error {
+				count, err := counter.Get("count")
+				if err != nil {
+					return err
+				}
+				if err = counter.Set("count", count.Int32()+1); err != nil {
+					return err
+				}
+				val, err := ctx.RunScript(fmt.Sprintf("'call %d'", i), "call.js")
+				if err != nil {
+					return err
+				}
+				results[i] = val.String()
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if expected := fmt.Sprintf("call %d", i); result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	}
+	err = e.Run(func(iso *v8.Isolate) error {
+		count, err := counter.Get("count")
+		if err == nil && count.Int32() != n {
+			err = fmt.Errorf("expected count %d, got %d", n, count.Int32())
+		}
+		ctx.Close()
+		return err
+	})
+	fatalIf(t, err)
+}
+
+func TestExecutorNestedRun(t *testing.T) {
+	t.Parallel()
+
+	e := v8.NewExecutor(v8.IsolateOptions{})
+	defer e.Close()
+
+	err := e.Run(func(iso *v8.Isolate) error {
+		ctx := v8.NewContext(iso)
+		defer ctx.Close()
+		fn := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+			var result *v8.Value
+			// A callback from JavaScript runs on the Executor's goroutine, so this doesn't block:
+			err := e.Run(func(iso *v8.Isolate) error {
+				var err error
+				result, err = v8.NewValue(iso, "nested")
+				return err
+			})
+			if err != nil {
+				t.Error(err)
+			}
+			return result
+		})
+		if err := ctx.Global().Set("nested", fn.GetFunction(ctx)); err != nil {
+			return err
+		}
+		val, err := ctx.RunScript("nested()", "nested.js")
+		if err == nil && val.String() != "nested" {
+			err = fmt.Errorf("unexpected result %q", val.String())
+		}
+		return err
+	})
+	fatalIf(t, err)
+}
+
+func TestExecutorErrorsAndPanics(t *testing.T) {
+	t.Parallel()
+
+	e := v8.NewExecutor(v8.IsolateOptions{})
+
+	oops := errors.New("oops")
+	if err := e.Run(func(iso *v8.Isolate) error { return oops }); err != oops {
+		t.Errorf("expected the function's error, got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected the panic to be re-raised, got %v", r)
+			}
+		}()
+		e.Run(func(iso *v8.Isolate) error { panic("boom") })
+	}()
+
+	// The Executor still works after a panic:
+	fatalIf(t, e.Run(func(iso *v8.Isolate) error { return nil }))
+
+	e.Close()
+	e.Close()
+	if err := e.Run(func(iso *v8.Isolate) error { return nil }); err == nil {
+		t.Error("expected an error from a closed Executor")
+	}
+}
+
+func TestExecutorGoexit(t *testing.T) {
+	t.Parallel()
+
+	e := v8.NewExecutor(v8.IsolateOptions{})
+	defer e.Close()
+
+	done := make(chan bool)
+	go func() {
+		returned := false
+		defer func() { done <- returned }()
+		_ = e.Run(func(iso *v8.Isolate) error {
+			runtime.Goexit()
+			return nil
+		})
+		returned = true
+	}()
+	if <-done {
+		t.Error("expected Run to call runtime.Goexit")
+	}
+
+	// The Executor still works, on a new goroutine:
+	err := e.Run(func(iso *v8.Isolate) error {
+		ctx := v8.NewContext(iso)
+		defer ctx.Close()
+		_, err := ctx.RunScript("1 + 1", "goexit.js")
+		return err
+	})
+	fatalIf(t, err)
+}
@@ -4,6 +4,8 @@
 
 package v8go
 
+import "sync/atomic"
+
 // RegisterCallback is exported for testing only.
 func (i *Isolate) RegisterCallback(cb FunctionCallback) int {
 	return i.registerCallback(cb)
@@ -13,3 +15,33 @@ func (i *Isolate) RegisterCallback(cb FunctionCallback) int {
 func (i *Isolate) GetCallback(ref int) FunctionCallback {
 	return i.getCallback(ref)
 }
+
+// CollectGarbage is exported for testing only.
+func (i *Isolate) CollectGarbage() {
+	i.lowMemoryNotification()
+}
+
+// ProxyHandlerCount is exported for testing only.
+func ProxyHandlerCount(ctx *Context) int {
+	return len(ctx.proxyHandlers)
+}
+
+// IteratorCount is exported for testing only.
+func IteratorCount(ctx *Context) int {
+	return len(ctx.iterators)
+}
+
+// UnboundScriptCount is exported for testing only.
+func (i *Isolate) UnboundScriptCount() int {
+	return int(atomic.LoadInt32(&i.liveScripts))
+}
+
+// FunctionCallbackCount is exported for testing only.
+func FunctionCallbackCount() int {
+	n := 0
+	goFunctionCallbacks.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
@@ -7,13 +7,53 @@ package v8go
 // #include "v8go.h"
 import "C"
 import (
+	"errors"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
 // Function is a JavaScript function.
 type Function struct {
 	*Value
+	compiled bool // Returned by Context.CompileFunction, so CreateCodeCache can be used
+}
+
+// NewFunction creates a JavaScript function, with the given name and `length`, that calls a Go
+// callback. Unlike a FunctionTemplate, which holds onto its callback for the life of the
+// Isolate, the callback is released when the function is garbage-collected or its Context is
+// closed; this makes NewFunction suitable for one-off callbacks.
+func NewFunction(ctx *Context, name string, callback FunctionCallback, length int) (*Function, error) {
+	if callback == nil {
+		return nil, errors.New("v8go: FunctionCallback is required")
+	}
+	nameVal, err := ctx.NewValue(name)
+	if err != nil {
+		return nil, err
+	}
+	id := atomic.AddInt32(&goFunctionCallbackSeq, 1)
+	goFunctionCallbacks.Store(id, callback)
+	rtn := C.NewFunction(ctx.ptr, nameVal.valuePtr(), C.int(id), C.int(length))
+	val, err := valueResult(ctx, rtn)
+	if err != nil {
+		goFunctionCallbacks.Delete(id)
+		return nil, err
+	}
+	return &Function{Value: val}, nil
+}
+
+var goFunctionCallbackSeq int32
+var goFunctionCallbacks sync.Map // Maps ID -> FunctionCallback, for NewFunction
+
+//export goNewFunctionCallback
+func goNewFunctionCallback(ctxHandle C.uintptr_t, id int, thisAndArgs *C.ValueRef, argsCount int) C.ValuePtr {
+	ctx := contextFromHandle(ctxHandle)
+	callback, ok := goFunctionCallbacks.Load(int32(id))
+	if !ok {
+		return throwError(ctx, GenericError, "function's callback has been released").valuePtr()
+	}
+	return callGoCallback(ctx, callback.(FunctionCallback), thisAndArgs, argsCount)
 }
 
 func convertArgs(args []Valuer) ([]C.ValuePtr, *C.ValuePtr) {
@@ -48,3 +88,123 @@ func (fn *Function) SourceMapUrl() *Value {
 	ptr := C.FunctionSourceMapUrl(fn.valuePtr())
 	return &Value{ptr, fn.ctx}
 }
+
+// CreateCodeCache creates a code cache for a function compiled by Context.CompileFunction,
+// which can be passed to a later CompileFunction call to speed it up. It must be called on the
+// Function that CompileFunction returned; for any other Function, including the same one got
+// again from a Value, it returns nil, since V8 can only cache functions it compiled that way.
+func (fn *Function) CreateCodeCache() *CompilerCachedData {
+	if !fn.compiled {
+		return nil
+	}
+	rtn := C.FunctionCreateCodeCache(fn.valuePtr())
+	if rtn == nil {
+		return nil
+	}
+	cachedData := &CompilerCachedData{
+		Bytes:    []byte(C.GoBytes(unsafe.Pointer(rtn.data), rtn.length)),
+		Rejected: int(rtn.rejected) == 1,
+	}
+	C.ScriptCompilerCachedDataDelete(rtn)
+	return cachedData
+}
+
+// Name returns the function's name, or "" if it's anonymous.
+func (fn *Function) Name() string {
+	return (&Value{C.FunctionGetName(fn.valuePtr()), fn.ctx}).String()
+}
+
+// SetName changes the function's name.
+func (fn *Function) SetName(name string) error {
+	nameVal, err := fn.ctx.NewValue(name)
+	if err != nil {
+		return err
+	}
+	C.FunctionSetName(fn.valuePtr(), nameVal.valuePtr())
+	return nil
+}
+
+// InferredName returns the name V8 inferred for an anonymous function from its context, such
+// as the variable or property it was assigned to, or "" if there is none.
+func (fn *Function) InferredName() string {
+	return (&Value{C.FunctionGetInferredName(fn.valuePtr()), fn.ctx}).String()
+}
+
+// DisplayName returns the name to show for the function in stack traces and debuggers:
+// its Name, or else its InferredName.
+func (fn *Function) DisplayName() string {
+	return (&Value{C.FunctionGetDebugName(fn.valuePtr()), fn.ctx}).String()
+}
+
+// ScriptOrigin returns the origin of the script in which the function was defined.
+// A function that isn't defined in a script (see IsNative) has an empty origin.
+func (fn *Function) ScriptOrigin() ScriptOrigin {
+	info := C.FunctionGetScriptOrigin(fn.valuePtr())
+	origin := ScriptOrigin{
+		LineOffset:   int(info.lineOffset),
+		ColumnOffset: int(info.columnOffset),
+		IsShared:     info.isShared != 0,
+		IsOpaque:     info.isOpaque != 0,
+		IsModule:     info.isModule != 0,
+	}
+	if name := (&Value{info.resourceName, fn.ctx}); name.IsString() {
+		origin.ResourceName = name.String()
+	}
+	if url := (&Value{info.sourceMapUrl, fn.ctx}); url.IsString() {
+		origin.SourceMapURL = url.String()
+	}
+	return origin
+}
+
+// ScriptId returns the ID of the script in which the function was defined, or 0 if none.
+// This is the same ID that appears in CPU profiles.
+func (fn *Function) ScriptId() int {
+	return int(C.FunctionScriptId(fn.valuePtr()))
+}
+
+// LineNumber returns the zero-based line number of the function's definition in its script,
+// or -1 if unknown.
+func (fn *Function) LineNumber() int {
+	return int(C.FunctionGetScriptLineNumber(fn.valuePtr()))
+}
+
+// ColumnNumber returns the zero-based column number of the function's definition in its
+// script, or -1 if unknown.
+func (fn *Function) ColumnNumber() int {
+	return int(C.FunctionGetScriptColumnNumber(fn.valuePtr()))
+}
+
+// Length returns the function's `length` property: the number of parameters it declares,
+// not counting rest parameters or those with default values. The property is configurable, so
+// a script may have redefined or deleted it; Length returns an error unless it's still an own
+// data property whose value is a number. It never calls a getter.
+func (fn *Function) Length() (int, error) {
+	obj, err := fn.AsObject()
+	if err != nil {
+		return 0, err
+	}
+	desc, err := obj.GetOwnPropertyDescriptor("length")
+	if err != nil {
+		return 0, err
+	}
+	if desc == nil || desc.Value == nil || !desc.Value.IsNumber() {
+		return 0, errors.New("v8go: function's length is not a number")
+	}
+	return int(desc.Value.Int32()), nil
+}
+
+// IsNative returns true if the function isn't defined in JavaScript source: it's a builtin,
+// or it's implemented in Go.
+func (fn *Function) IsNative() bool {
+	return C.FunctionIsNative(fn.valuePtr()) != 0
+}
+
+// GetBoundFunction returns the target function of a function created by
+// `Function.prototype.bind`, or nil if this function isn't bound.
+func (fn *Function) GetBoundFunction() *Function {
+	target := &Value{C.FunctionGetBoundFunction(fn.valuePtr()), fn.ctx}
+	if !target.IsFunction() {
+		return nil
+	}
+	return &Function{Value: target}
+}
@@ -37,6 +37,12 @@ func (i *FunctionCallbackInfo) Args() []*Value {
 	return i.args
 }
 
+// Unlocked calls fn with the Isolate unlocked, so that other goroutines can use it while fn
+// blocks; see Isolate.WithUnlocked. fn must not use the Isolate or its Values.
+func (i *FunctionCallbackInfo) Unlocked(fn func()) {
+	i.ctx.iso.WithUnlocked(fn)
+}
+
 // FunctionTemplate is used to create functions at runtime.
 // There can only be one function created from a FunctionTemplate in a context.
 // The lifetime of the created function is equal to the lifetime of the context.
@@ -71,7 +77,7 @@ func (tmpl *FunctionTemplate) GetFunction(ctx *Context) *Function {
 	if err != nil {
 		panic(err) // TODO: Consider returning the error
 	}
-	return &Function{val}
+	return &Function{Value: val}
 }
 
 // Note that ideally `thisAndArgs` would be split into two separate arguments, but they were combined
@@ -79,6 +85,11 @@ func (tmpl *FunctionTemplate) GetFunction(ctx *Context) *Function {
 //export goFunctionCallback
 func goFunctionCallback(ctxHandle C.uintptr_t, cbref int, thisAndArgs *C.ValueRef, argsCount int) C.ValuePtr {
 	ctx := contextFromHandle(ctxHandle)
+	return callGoCallback(ctx, ctx.iso.getCallback(cbref), thisAndArgs, argsCount)
+}
+
+// Calls a FunctionCallback with the `this` and arguments passed from C++.
+func callGoCallback(ctx *Context, callbackFunc FunctionCallback, thisAndArgs *C.ValueRef, argsCount int) C.ValuePtr {
 	this := *thisAndArgs
 	info := &FunctionCallbackInfo{
 		ctx:  ctx,
@@ -94,7 +105,6 @@ func goFunctionCallback(ctxHandle C.uintptr_t, cbref int, thisAndArgs *C.ValueRe
 		}
 	}
 
-	callbackFunc := ctx.iso.getCallback(cbref)
 	if val := callbackFunc(info); val != nil {
 		return val.valuePtr()
 	}
@@ -11,6 +11,7 @@
 package v8go_test
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -24,19 +25,19 @@ func ExampleFunctionTemplate_fetch() {
 	defer iso.Dispose()
 	global := v8.NewObjectTemplate(iso)
 
-	fetchfn := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
-		args := info.Args()
-		url := args[0].String()
-
-		resolver, _ := v8.NewPromiseResolver(info.Context())
-
-		go func() {
-			res, _ := http.Get(url)
-			body, _ := ioutil.ReadAll(res.Body)
-			val, _ := v8.NewValue(iso, string(body))
-			resolver.Resolve(val)
-		}()
-		return resolver.GetPromise().Value
+	fetchfn := v8.NewAsyncFunctionTemplate(iso, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		url, _ := args[0].(string)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		return string(body), err
 	})
 	global.Set("fetch", fetchfn, v8.ReadOnly)
 
@@ -46,9 +47,7 @@ func ExampleFunctionTemplate_fetch() {
 	prom, _ := val.AsPromise()
 
 	// wait for the promise to resolve
-	for prom.State() == v8.Pending {
-		continue
-	}
+	iso.WaitAsyncCalls(context.Background())
 	fmt.Printf("%s\n", strings.Split(prom.Result().String(), "\n")[0])
 	// Output:
 	// <!DOCTYPE html>
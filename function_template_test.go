@@ -7,6 +7,7 @@ package v8go_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	v8 "github.com/couchbasedeps/v8go"
 )
@@ -124,3 +125,68 @@ func ExampleFunctionTemplate() {
 	// Output:
 	// [foo bar 0 1]
 }
+
+func TestFunctionCallbackInfoUnlocked(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx1 := v8.NewContext(iso)
+	defer ctx1.Close()
+	ctx2 := v8.NewContext(iso)
+	defer ctx2.Close()
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	block := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+		info.Unlocked(func() {
+			close(entered)
+			<-release
+		})
+		val, _ := v8.NewValue(iso, "unblocked")
+		return val
+	})
+	fatalIf(t, ctx1.Global().Set("block", block.GetFunction(ctx1)))
+
+	result := make(chan string)
+	go func() {
+		iso.Lock()
+		val, err := ctx1.RunScript("block()", "block.js")
+		str := ""
+		if err != nil {
+			t.Error(err)
+		} else {
+			str = val.String()
+		}
+		iso.Unlock()
+		result <- str
+	}()
+	<-entered
+
+	// While the callback blocks, another goroutine can lock the Isolate and run a script:
+	ran := make(chan struct{})
+	go func() {
+		defer close(ran)
+		iso.Lock()
+		defer iso.Unlock()
+		val, err := ctx2.RunScript("2 + 2", "other.js")
+		if err != nil {
+			t.Error(err)
+		} else if val.Int32() != 4 {
+			t.Errorf("expected 4, got %v", val)
+		}
+	}()
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("script didn't run while the callback had unlocked the Isolate")
+	}
+
+	close(release)
+	if r := <-result; r != "unblocked" {
+		t.Errorf("unexpected result %q", r)
+	}
+	if iso.IsLocked() {
+		t.Error("expected the Isolate to be unlocked")
+	}
+}
@@ -202,3 +202,140 @@ func TestFunctionNewInstanceError(t *testing.T) {
 		t.Errorf("want %+v, got: %+v", want, got)
 	}
 }
+
+func TestFunctionIntrospection(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	_, err := ctx.RunScript("var obj = {};\n\nfunction add(a, b, c = 0) { return a + b + c; }\n  obj.helper = function() {};", "plugin.js")
+	fatalIf(t, err)
+	getFunction := func(source string) *v8.Function {
+		val, err := ctx.RunScript(source, "")
+		fatalIf(t, err)
+		fn, err := val.AsFunction()
+		fatalIf(t, err)
+		return fn
+	}
+
+	add := getFunction("add")
+	if add.Name() != "add" || add.DisplayName() != "add" {
+		t.Errorf("unexpected name %q / display name %q", add.Name(), add.DisplayName())
+	}
+	if n, err := add.Length(); err != nil || n != 2 {
+		t.Errorf("expected length 2, got %d, %v", n, err)
+	}
+	for _, redefine := range []string{
+		"Object.defineProperty(function(a) {}, 'length', {get() { throw new Error('called') }})",
+		"Object.defineProperty(function(a) {}, 'length', {value: 'one'})",
+		"(function() { const f = function(a) {}; delete f.length; return f })()",
+	} {
+		if _, err := getFunction(redefine).Length(); err == nil {
+			t.Errorf("expected error from Length of %s", redefine)
+		}
+	}
+	if add.LineNumber() != 2 || add.ColumnNumber() < 0 {
+		t.Errorf("unexpected location %d:%d", add.LineNumber(), add.ColumnNumber())
+	}
+	if origin := add.ScriptOrigin(); origin.ResourceName != "plugin.js" || origin.IsModule {
+		t.Errorf("unexpected origin %+v", origin)
+	}
+	if add.ScriptId() == 0 || add.IsNative() {
+		t.Error("expected a script function to have a script ID and not be native")
+	}
+
+	helper := getFunction("obj.helper")
+	if helper.Name() != "" || helper.InferredName() != "obj.helper" || helper.DisplayName() != "obj.helper" {
+		t.Errorf("unexpected names %q, %q, %q", helper.Name(), helper.InferredName(), helper.DisplayName())
+	}
+	fatalIf(t, helper.SetName("renamed"))
+	if helper.Name() != "renamed" {
+		t.Errorf("expected name to be changed, got %q", helper.Name())
+	}
+
+	if bound := getFunction("add.bind(null, 1)"); bound.GetBoundFunction() == nil ||
+		!bound.GetBoundFunction().SameValue(add.Value) {
+		t.Error("expected GetBoundFunction to return the target")
+	} else if bound.IsNative() {
+		t.Error("expected bound function not to be native")
+	}
+	if add.GetBoundFunction() != nil {
+		t.Error("expected nil bound function for an unbound function")
+	}
+
+	native := getFunction("Math.max")
+	if !native.IsNative() || native.ScriptId() != 0 || native.LineNumber() != -1 {
+		t.Error("expected builtin to be native, with no script or location")
+	}
+	goFn := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value { return nil })
+	if !goFn.GetFunction(ctx).IsNative() {
+		t.Error("expected Go function to be native")
+	}
+}
+
+func TestNewFunction(t *testing.T) {
+	// Not parallel, since it checks the global count of callbacks.
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+
+	before := v8.FunctionCallbackCount()
+	double, err := v8.NewFunction(ctx, "double", func(info *v8.FunctionCallbackInfo) *v8.Value {
+		val, _ := v8.NewValue(iso, info.Args()[0].Int32()*2)
+		return val
+	}, 1)
+	fatalIf(t, err)
+	if n, _ := double.Length(); double.Name() != "double" || n != 1 || !double.IsNative() {
+		t.Errorf("unexpected function %q with length %d", double.Name(), n)
+	}
+	if v8.FunctionCallbackCount() != before+1 {
+		t.Errorf("expected one more registered callback")
+	}
+
+	arr, err := ctx.RunScript("[1, 2, 3]", "")
+	fatalIf(t, err)
+	arrObj, _ := arr.AsObject()
+	result, err := arrObj.MethodCall("map", double)
+	fatalIf(t, err)
+	if s := result.String(); s != "2,4,6" {
+		t.Errorf("expected 2,4,6, got %q", s)
+	}
+
+	ctx.Close()
+	if v8.FunctionCallbackCount() != before {
+		t.Errorf("expected callback to be released when the Context closed")
+	}
+
+	if _, err := v8.NewFunction(ctx, "nil", nil, 0); err == nil {
+		t.Error("expected error with a nil callback")
+	}
+}
+
+func TestNewFunctionReleasedByGC(t *testing.T) {
+	// Not parallel, since it checks the global count of callbacks.
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	before := v8.FunctionCallbackCount()
+	ctx.WithTemporaryValues(func() {
+		for i := 0; i < 10; i++ {
+			_, err := v8.NewFunction(ctx, "dropped", func(info *v8.FunctionCallbackInfo) *v8.Value {
+				return nil
+			}, 0)
+			fatalIf(t, err)
+		}
+	})
+	if v8.FunctionCallbackCount() != before+10 {
+		t.Fatalf("expected 10 more registered callbacks")
+	}
+
+	// The callbacks are released when V8 collects the unreachable functions:
+	iso.CollectGarbage()
+	if n := v8.FunctionCallbackCount(); n != before {
+		t.Errorf("expected the callbacks to be released, %d remain", n-before)
+	}
+}
@@ -11,7 +11,22 @@ static constexpr size_t MB = 1024 * 1024;
 static constexpr size_t kGrowHeapBy  =  1 * MB; // Amount to grow by on every callback
 
 static auto default_platform = platform::NewDefaultPlatform();
-static auto default_allocator = ArrayBuffer::Allocator::NewDefaultAllocator();
+
+// Isolate data slots:
+static constexpr uint32_t kInternalContextSlot = 0;
+static constexpr uint32_t kAllocatorSlot = 1;
+static constexpr uint32_t kTerminatedSlot = 2;
+
+// Flag set whenever execution is terminated, since IsExecutionTerminating is only true while
+// JavaScript frames are still on the stack.
+static inline std::atomic<bool>* terminatedFlag(Isolate* iso) {
+  return static_cast<std::atomic<bool>*>(iso->GetData(kTerminatedSlot));
+}
+
+static void terminateExecution(Isolate* iso) {
+  terminatedFlag(iso)->store(true);
+  iso->TerminateExecution();
+}
 
 void Init() {
 #ifdef _WIN32
@@ -37,7 +52,7 @@ static size_t nearHeapLimitCallback(void* data, size_t cur, size_t initialLimit)
     return std::min(cur + kGrowHeapBy, maxHeap);
   } else if (cur < maxHeap + kGrowHeapBy) {
     fprintf(stderr, "***** V8 EXCEEDED HEAP LIMIT of %zuMB; terminating script\n", maxHeap);
-    reinterpret_cast<Isolate*>(data)->TerminateExecution();
+    terminateExecution(reinterpret_cast<Isolate*>(data));
     return cur + kGrowHeapBy;
   } else {
     fprintf(stderr, "***** V8 EXCEEDED HEAP LIMIT AND WON'T STOP; aborting\n");
@@ -46,13 +61,72 @@ static size_t nearHeapLimitCallback(void* data, size_t cur, size_t initialLimit)
 }
 
 
-NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit) {
+/********** V8GoAllocator **********/
+
+namespace v8go {
+
+  V8GoAllocator::V8GoAllocator(size_t limit)
+  :_base(ArrayBuffer::Allocator::NewDefaultAllocator())
+  ,_limit(limit > 0 ? limit : SIZE_MAX)
+  { }
+
+  V8GoAllocator* V8GoAllocator::forIsolate(Isolate* iso) {
+    return static_cast<V8GoAllocator*>(iso->GetData(kAllocatorSlot));
+  }
+
+  bool V8GoAllocator::reserve(size_t length) {
+    size_t used = _used.load();
+    do {
+      if (length > _limit - used) {
+        return false;
+      }
+    } while (!_used.compare_exchange_weak(used, used + length));
+    return true;
+  }
+
+  void* V8GoAllocator::Allocate(size_t length) {
+    if (!reserve(length)) {
+      return nullptr;
+    }
+    void* data = _base->Allocate(length);
+    if (!data) {
+      _used -= length;
+    }
+    return data;
+  }
+
+  void* V8GoAllocator::AllocateUninitialized(size_t length) {
+    if (!reserve(length)) {
+      return nullptr;
+    }
+    void* data = _base->AllocateUninitialized(length);
+    if (!data) {
+      _used -= length;
+    }
+    return data;
+  }
+
+  void V8GoAllocator::Free(void* data, size_t length) {
+    _base->Free(data, length);
+    _used -= length;
+  }
+
+}
+
+
+NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit, size_t arrayBufferLimit) {
   Isolate::CreateParams params;
   if (initialHeap > 0 && heapLimit > 0) {
     params.constraints.ConfigureDefaultsFromHeapSize(initialHeap, heapLimit - 2 * kGrowHeapBy);
   }
-  params.array_buffer_allocator = default_allocator;
+  // The Isolate shares ownership of the allocator with any BackingStores it allocates, so the
+  // allocator outlives the Isolate if an ArrayBuffer's memory is still in use elsewhere.
+  auto allocator = std::make_shared<V8GoAllocator>(arrayBufferLimit);
+  params.array_buffer_allocator_shared = allocator;
+  params.array_buffer_allocator = allocator.get();
   Isolate* iso = Isolate::New(params);
+  iso->SetData(kAllocatorSlot, allocator.get());
+  iso->SetData(kTerminatedSlot, new std::atomic<bool>(false));
   WithIsolate _with(iso);
 
   iso->SetCaptureStackTraceForUncaughtExceptions(true);
@@ -63,7 +137,7 @@ NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit) {
 
   // Create a Context for internal use
   V8GoContext* ctx = new V8GoContext(iso, Context::New(iso), 0);
-  iso->SetData(0, ctx);
+  iso->SetData(kInternalContextSlot, ctx);
 
   NewIsolateResult result;
   result.isolate = iso;
@@ -76,7 +150,7 @@ NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit) {
 }
 
 static inline V8GoContext* isolateInternalContext(Isolate* iso) {
-  return static_cast<V8GoContext*>(iso->GetData(0));
+  return static_cast<V8GoContext*>(iso->GetData(kInternalContextSlot));
 }
 
 WithIsolatePtr IsolateLock(Isolate *iso) {
@@ -87,6 +161,21 @@ void IsolateUnlock(WithIsolatePtr w) {
   delete w;
 }
 
+int IsolateIsLockedByCurrentThread(IsolatePtr iso) {
+  return Locker::IsLocked(iso);
+}
+
+UnlockerPtr IsolateNewUnlocker(IsolatePtr iso) {
+  if (!Locker::IsLocked(iso)) {
+    return nullptr;  // This thread doesn't hold the lock, so there's nothing to release
+  }
+  return new Unlocker(iso);
+}
+
+void UnlockerFree(UnlockerPtr unlocker) {
+  delete unlocker;  // Waits to re-acquire the isolate's lock
+}
+
 void IsolatePerformMicrotaskCheckpoint(IsolatePtr iso) {
   WithIsolate _withiso(iso);
   iso->PerformMicrotaskCheckpoint();
@@ -97,12 +186,22 @@ void IsolateDispose(IsolatePtr iso) {
     return;
   }
   ContextFree(isolateInternalContext(iso));
+  delete terminatedFlag(iso);
 
   iso->Dispose();
 }
 
 void IsolateTerminateExecution(IsolatePtr iso) {
-  iso->TerminateExecution();
+  terminateExecution(iso);
+}
+
+int IsolateTakeTerminated(IsolatePtr iso) {
+  return terminatedFlag(iso)->exchange(false);
+}
+
+void IsolateLowMemoryNotification(IsolatePtr iso) {
+  WithIsolate _withiso(iso);
+  iso->LowMemoryNotification();
 }
 
 int IsolateIsExecutionTerminating(IsolatePtr iso) {
@@ -115,6 +214,7 @@ IsolateHStatistics IsolationGetHeapStatistics(IsolatePtr iso) {
   }
   v8::HeapStatistics hs;
   iso->GetHeapStatistics(&hs);
+  V8GoAllocator* allocator = V8GoAllocator::forIsolate(iso);
 
   return IsolateHStatistics{hs.total_heap_size(),
                             hs.total_heap_size_executable(),
@@ -126,7 +226,14 @@ IsolateHStatistics IsolationGetHeapStatistics(IsolatePtr iso) {
                             hs.external_memory(),
                             hs.peak_malloced_memory(),
                             hs.number_of_native_contexts(),
-                            hs.number_of_detached_contexts()};
+                            hs.number_of_detached_contexts(),
+                            allocator->used(),
+                            allocator->limit() == SIZE_MAX ? 0 : allocator->limit()};
+}
+
+void IsolateSetAllowAtomicsWait(IsolatePtr iso, Bool allow) {
+  WithIsolate _withiso(iso);
+  iso->SetAllowAtomicsWait(allow);
 }
 
 ValueRef IsolateThrowException(IsolatePtr iso, ValuePtr value) {
@@ -166,7 +273,7 @@ RtnUnboundScript IsolateCompileUnboundScript(IsolatePtr iso,
                                                  opts.cachedData.length);
   }
 
-  ScriptOrigin script_origin(ogn);
+  ScriptOrigin script_origin = NewScriptOrigin(iso, opts.origin, ogn);
 
   ScriptCompiler::Source source(src, script_origin, cached_data);
 
@@ -227,6 +334,182 @@ RtnValue UnboundScriptRun(ContextPtr ctx, UnboundScriptPtr us_ptr) {
   return rtn;
 }
 
+void UnboundScriptFree(IsolatePtr iso, UnboundScriptPtr us_ptr) {
+  WithIsolate _withiso(iso);
+  isolateInternalContext(iso)->freeUnboundScript(us_ptr);
+}
+
+int UnboundScriptGetId(IsolatePtr iso, UnboundScriptPtr us_ptr) {
+  WithIsolate _withiso(iso);
+  return us_ptr->ptr.Get(iso)->GetId();
+}
+
+// Returns a malloc-ed copy of the value if it's a string, else an empty RtnString.
+static RtnString copyIfString(Isolate* iso, Local<Value> val) {
+  if (val.IsEmpty() || !val->IsString()) {
+    return {};
+  }
+  return CopyString(iso, val.As<String>());
+}
+
+RtnString UnboundScriptGetScriptName(IsolatePtr iso, UnboundScriptPtr us_ptr) {
+  WithIsolate _withiso(iso);
+  return copyIfString(iso, us_ptr->ptr.Get(iso)->GetScriptName());
+}
+
+RtnString UnboundScriptGetSourceURL(IsolatePtr iso, UnboundScriptPtr us_ptr) {
+  WithIsolate _withiso(iso);
+  return copyIfString(iso, us_ptr->ptr.Get(iso)->GetSourceURL());
+}
+
+RtnString UnboundScriptGetSourceMappingURL(IsolatePtr iso, UnboundScriptPtr us_ptr) {
+  WithIsolate _withiso(iso);
+  return copyIfString(iso, us_ptr->ptr.Get(iso)->GetSourceMappingURL());
+}
+
+int UnboundScriptGetLineNumber(IsolatePtr iso, UnboundScriptPtr us_ptr, int code_pos) {
+  WithIsolate _withiso(iso);
+  return us_ptr->ptr.Get(iso)->GetLineNumber(code_pos);
+}
+
+uint32_t CachedDataVersionTag() {
+  return ScriptCompiler::CachedDataVersionTag();
+}
+
+/********** ScriptStreamer **********/
+
+namespace v8go {
+  // Compiles a script while its source is read from a Go io.Reader, on a background thread.
+  // Without a code cache, V8 parses the source as it's streamed in; with one, the source is
+  // read and the cache deserialized in the background, then checked by ScriptStreamerFinish.
+  struct V8GoScriptStreamer {
+    static constexpr size_t kChunkSize = 64 * 1024;
+
+    // Reads the next chunk of source from Go, also appending it to `sourceText`.
+    class SourceStream : public ScriptCompiler::ExternalSourceStream {
+    public:
+      explicit SourceStream(V8GoScriptStreamer* s) :_streamer(s) { }
+
+      size_t GetMoreData(const uint8_t** src) override {
+        auto buf = new uint8_t[kChunkSize];
+        size_t n = goReadScriptSource(_streamer->readerHandle, buf, kChunkSize);
+        if (n == 0) {
+          delete[] buf;
+          *src = nullptr;
+          return 0;
+        }
+        _streamer->sourceText.append((const char*)buf, n);
+        *src = buf;  // V8 takes ownership
+        return n;
+      }
+
+    private:
+      V8GoScriptStreamer* _streamer;
+    };
+
+    Isolate* iso;
+    uintptr_t readerHandle;  // a runtime.cgo.Handle pointing to the Go ScriptStreamer
+    std::string sourceText;
+    std::unique_ptr<ScriptCompiler::StreamedSource> streamedSource;
+    std::unique_ptr<ScriptCompiler::ScriptStreamingTask> streamingTask;
+    std::vector<uint8_t> cacheBytes;
+    std::unique_ptr<ScriptCompiler::ConsumeCodeCacheTask> consumeTask;
+  };
+}
+
+ScriptStreamerPtr NewScriptStreamer(IsolatePtr iso, uintptr_t readerHandle,
+                                    CompileOptions opts) {
+  WithIsolate _withiso(iso);
+  auto s = new V8GoScriptStreamer{iso, readerHandle};
+  if (opts.cachedData.data) {
+    s->cacheBytes.assign(opts.cachedData.data, opts.cachedData.data + opts.cachedData.length);
+    auto cached_data = std::make_unique<ScriptCompiler::CachedData>(s->cacheBytes.data(),
+                                                                     s->cacheBytes.size());
+    s->consumeTask.reset(ScriptCompiler::StartConsumingCodeCache(iso, std::move(cached_data)));
+  } else {
+    s->streamedSource = std::make_unique<ScriptCompiler::StreamedSource>(
+        std::make_unique<V8GoScriptStreamer::SourceStream>(s),
+        ScriptCompiler::StreamedSource::UTF8);
+    s->streamingTask.reset(ScriptCompiler::StartStreaming(iso, s->streamedSource.get()));
+  }
+  return s;
+}
+
+// Runs on a background thread, without the isolate lock.
+void ScriptStreamerRun(ScriptStreamerPtr s) {
+  if (s->streamingTask) {
+    s->streamingTask->Run();
+  } else {
+    // Not streaming, so just read all of the source:
+    V8GoScriptStreamer::SourceStream stream(s);
+    const uint8_t* chunk;
+    while (stream.GetMoreData(&chunk) > 0) {
+      delete[] chunk;
+    }
+    if (s->consumeTask) {
+      s->consumeTask->Run();
+    }
+  }
+}
+
+RtnUnboundScript ScriptStreamerFinish(ScriptStreamerPtr s,
+                                      const char* o, int oLen,
+                                      CompileOptions opts) {
+  Isolate* iso = s->iso;
+  V8GoContext *ctx = isolateInternalContext(iso);
+  WithContext _with(ctx);
+
+  RtnUnboundScript rtn = {};
+
+  Local<String> src, ogn;
+  if (!String::NewFromUtf8(iso, s->sourceText.data(), NewStringType::kNormal,
+                           int(s->sourceText.size())).ToLocal(&src) ||
+      !String::NewFromUtf8(iso, o, NewStringType::kNormal, oLen).ToLocal(&ogn)) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  std::string().swap(s->sourceText);  // V8 has its own copy now
+  ScriptOrigin script_origin = NewScriptOrigin(iso, opts.origin, ogn);
+
+  Local<UnboundScript> unbound_script;
+  if (s->streamedSource && s->streamingTask) {
+    Local<Script> script;
+    if (!ScriptCompiler::Compile(_with.local_ctx, s->streamedSource.get(), src, script_origin)
+             .ToLocal(&script)) {
+      rtn.error = _with.exceptionError();
+      return rtn;
+    }
+    unbound_script = script->GetUnboundScript();
+  } else {
+    // The code cache path, or V8 declined to stream the script:
+    ScriptCompiler::CompileOptions option =
+        static_cast<ScriptCompiler::CompileOptions>(opts.compileOption);
+    ScriptCompiler::CachedData* cached_data = nullptr;
+    if (s->consumeTask) {
+      cached_data = new ScriptCompiler::CachedData(s->cacheBytes.data(),
+                                                   int(s->cacheBytes.size()));
+    } else if (option == ScriptCompiler::kConsumeCodeCache) {
+      option = ScriptCompiler::kNoCompileOptions;
+    }
+    ScriptCompiler::Source source(src, script_origin, cached_data, s->consumeTask.release());
+    if (!ScriptCompiler::CompileUnboundScript(iso, &source, option).ToLocal(&unbound_script)) {
+      rtn.error = _with.exceptionError();
+      return rtn;
+    }
+    if (cached_data) {
+      rtn.cachedDataRejected = cached_data->rejected;
+    }
+  }
+
+  rtn.ptr = ctx->newUnboundScript(std::move(unbound_script));
+  return rtn;
+}
+
+void ScriptStreamerFree(ScriptStreamerPtr s) {
+  WithIsolate _withiso(s->iso);
+  delete s;
+}
+
 /********** CpuProfiler **********/
 
 CPUProfiler* NewCPUProfiler(IsolatePtr iso) {
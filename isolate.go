@@ -15,8 +15,11 @@ static RtnUnboundScript IsolateCompileUnboundScriptGo(IsolatePtr iso,
 import "C"
 
 import (
+	"context"
+	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -29,14 +32,35 @@ type Isolate struct {
 	ptr             C.IsolatePtr // V8 Isolate*
 	internalContext *Context     // Default Context
 
-	v8Mutex sync.Mutex       // Mutex for Lock() and Unlock() methods
-	v8Lock  C.WithIsolatePtr // Holds native lock state between Lock() and Unlock()
+	v8Mutex   sync.Mutex       // Mutex for Lock() and Unlock() methods
+	v8Lock    C.WithIsolatePtr // Holds native lock state between Lock() and Unlock()
+	lockHeld  int32            // 1 while a goroutine holds the lock, else 0 (atomic)
+	lockDepth int              // Number of nested Lock() calls by the owner
+	lockOwner int64            // ID of the goroutine holding the lock, if checkOwnership (atomic)
+	lastUser  int64            // ID of the goroutine that last used it, if checkOwnership (atomic)
 
 	cbMutex sync.RWMutex             // Mutex for accessing `cbs`
 	cbSeq   int                      // Latest ID assigned to a callback
 	cbs     map[int]FunctionCallback // Array of registered callbacks
 
-	stringBuffer []byte // Temporary scratch space for cgo to copy strings to
+	codeCache *CodeCache // Consulted by CompileUnboundScript, if not nil
+
+	finalizedMutex   sync.Mutex           // Mutex for accessing `finalizedScripts`
+	finalizedScripts []C.UnboundScriptPtr // Garbage-collected UnboundScripts not yet freed
+	liveScripts      int32                // Number of UnboundScripts not yet freed (atomic)
+
+	asyncMutex   sync.Mutex      // Mutex for the fields below
+	asyncCtx     context.Context // Parent of the Go contexts of async calls, created on demand
+	asyncCancel  func()          // Cancels asyncCtx
+	asyncPending int             // Number of async calls whose Promises aren't settled
+	asyncResults []*asyncCall    // Finished async calls waiting for SettleAsyncCalls
+	asyncReady   chan struct{}   // Signaled when an async call finishes
+
+	workerTmpl       *ObjectTemplate              // Template of Worker objects, created on demand
+	iteratorTmpl     *ObjectTemplate              // Template of NewIterable objects, created on demand
+	iteratorSelfTmpl *FunctionTemplate            // Their `Symbol.iterator` method
+	proxyHandlerTmpl *ObjectTemplate              // Template of NewProxyFunc handlers, created on demand
+	proxyTrapTmpls   map[string]*FunctionTemplate // Their trap methods, by name
 
 	null      *Value // Cached Value of `null`
 	undefined *Value // Cached Value of `undefined`
@@ -57,10 +81,34 @@ type HeapStatistics struct {
 	PeakMallocedMemory       uint64
 	NumberOfNativeContexts   uint64
 	NumberOfDetachedContexts uint64
+	ArrayBufferMemory        uint64 // Bytes currently allocated for ArrayBuffer contents
+	ArrayBufferMemoryLimit   uint64 // IsolateOptions.MaxArrayBufferMemory; 0 if unlimited
+}
+
+// IsolateOptions are the settings for creating an Isolate with NewIsolateWithOptions.
+// The zero value gives the default settings.
+type IsolateOptions struct {
+	// Initial and maximum heap sizes in bytes; see NewIsolateWith.
+	InitialHeap uint64
+	MaxHeap     uint64
+
+	// The maximum number of bytes the Isolate may allocate for the contents of ArrayBuffers
+	// (including typed arrays), which live outside the JS heap and aren't limited by MaxHeap.
+	// Allocations beyond the limit fail with a RangeError. Zero means no limit.
+	MaxArrayBufferMemory uint64
+
+	// If non-nil, CompileUnboundScript loads and saves code caches in this CodeCache.
+	CodeCache *CodeCache
 }
 
 const kIsolateStringBufferSize = 1024
 
+// Temporary scratch space for cgo to copy strings to. Buffers aren't shared, so Values of an
+// Isolate can be converted to strings on different goroutines without racing.
+var stringBuffers = sync.Pool{
+	New: func() interface{} { return new([kIsolateStringBufferSize]byte) },
+}
+
 // NewIsolate creates a new V8 isolate. Only one thread may access
 // a given isolate at a time, but different threads may access
 // different isolates simultaneously.
@@ -79,14 +127,21 @@ func NewIsolate() *Isolate {
 // The heap sizes are given in bytes. If both are zero, the default
 // heap settings are used.
 func NewIsolateWith(initialHeap uint64, maxHeap uint64) *Isolate {
+	return NewIsolateWithOptions(IsolateOptions{InitialHeap: initialHeap, MaxHeap: maxHeap})
+}
+
+// NewIsolateWithOptions creates a new V8 isolate with the given options.
+func NewIsolateWithOptions(opts IsolateOptions) *Isolate {
 	v8once.Do(func() {
 		C.Init()
 	})
-	result := C.NewIsolate(C.size_t(initialHeap), C.size_t(maxHeap))
+	result := C.NewIsolate(C.size_t(opts.InitialHeap), C.size_t(opts.MaxHeap),
+		C.size_t(opts.MaxArrayBufferMemory))
 	iso := &Isolate{
-		ptr:          result.isolate,
-		cbs:          make(map[int]FunctionCallback),
-		stringBuffer: make([]byte, kIsolateStringBufferSize),
+		ptr:        result.isolate,
+		cbs:        make(map[int]FunctionCallback),
+		codeCache:  opts.CodeCache,
+		asyncReady: make(chan struct{}, 1),
 	}
 	iso.internalContext = &Context{
 		ptr: result.internalContext,
@@ -112,18 +167,33 @@ func (i *Isolate) IsExecutionTerminating() bool {
 	return C.IsolateIsExecutionTerminating(i.ptr) == 1
 }
 
+// Returns whether execution has been terminated, by TerminateExecution or by exceeding the
+// heap limit, since the last call, and clears the flag.
+func (i *Isolate) takeTerminated() bool {
+	return C.IsolateTakeTerminated(i.ptr) != 0
+}
+
+// Tells V8 that memory is low, which makes it run a full garbage collection.
+func (i *Isolate) lowMemoryNotification() {
+	C.IsolateLowMemoryNotification(i.ptr)
+}
+
 type CompileOptions struct {
 	CachedData *CompilerCachedData
 
 	Mode CompileMode
+
+	// Origin describes where the source came from. If it's nil, or its ResourceName is empty,
+	// the origin (file name) passed to the compile function is used.
+	Origin *ScriptOrigin
+
+	// If true, the UnboundScript keeps a copy of its source, which UnboundScript.Source returns.
+	RetainSource bool
 }
 
-// CompileUnboundScript will create an UnboundScript (i.e. context-indepdent)
-// using the provided source JavaScript, origin (a.k.a. filename), and options.
-// If options contain a non-null CachedData, compilation of the script will use
-// that code cache.
-// error will be of type `JSError` if not nil.
-func (i *Isolate) CompileUnboundScript(source, origin string, opts CompileOptions) (*UnboundScript, error) {
+// Converts the options to a C struct. The cached data, if any, is not copied.
+// The returned function must be called after the options have been used, to free them.
+func (opts CompileOptions) toC() (C.CompileOptions, func(), error) {
 	var cOptions C.CompileOptions
 	if opts.CachedData != nil {
 		if opts.Mode != 0 {
@@ -137,18 +207,54 @@ func (i *Isolate) CompileUnboundScript(source, origin string, opts CompileOption
 	} else {
 		cOptions.compileOption = C.int(opts.Mode)
 	}
+	origin, free, err := opts.Origin.toC()
+	if err != nil {
+		return cOptions, nil, err
+	}
+	cOptions.origin = origin
+	return cOptions, free, nil
+}
 
+// CompileUnboundScript will create an UnboundScript (i.e. context-indepdent)
+// using the provided source JavaScript, origin (a.k.a. filename), and options.
+// If options contain a non-null CachedData, compilation of the script will use
+// that code cache.
+// error will be of type `JSError` if not nil.
+func (i *Isolate) CompileUnboundScript(source, origin string, opts CompileOptions) (*UnboundScript, error) {
+	var cacheKey string
+	if i.codeCache != nil && opts.CachedData == nil && opts.Mode == CompileModeDefault {
+		cacheKey = i.codeCache.key(source, origin, opts.Origin)
+		opts.CachedData = i.codeCache.load(cacheKey)
+	}
+
+	cOptions, free, err := opts.toC()
+	if err != nil {
+		return nil, err
+	}
+	i.freeFinalizedScripts()
 	rtn := C.IsolateCompileUnboundScriptGo(i.ptr, source, origin, cOptions)
+	free()
 	if rtn.ptr == nil {
 		return nil, newJSError(rtn.error)
 	}
 	if opts.CachedData != nil {
 		opts.CachedData.Rejected = int(rtn.cachedDataRejected) == 1
 	}
-	return &UnboundScript{
-		ptr: rtn.ptr,
-		iso: i,
-	}, nil
+	if !opts.RetainSource {
+		source = ""
+	}
+	script := newUnboundScript(i, rtn.ptr, source)
+	if cacheKey != "" {
+		script.cacheKey = cacheKey
+		if opts.CachedData == nil || opts.CachedData.Rejected {
+			// Save a new cache after the script first runs:
+			script.cacheNeedsSave = true
+			if opts.CachedData != nil {
+				i.codeCache.evict(cacheKey)
+			}
+		}
+	}
+	return script, nil
 }
 
 // GetHeapStatistics returns heap statistics for an isolate.
@@ -167,6 +273,8 @@ func (i *Isolate) GetHeapStatistics() HeapStatistics {
 		PeakMallocedMemory:       uint64(hs.peak_malloced_memory),
 		NumberOfNativeContexts:   uint64(hs.number_of_native_contexts),
 		NumberOfDetachedContexts: uint64(hs.number_of_detached_contexts),
+		ArrayBufferMemory:        uint64(hs.array_buffer_memory),
+		ArrayBufferMemoryLimit:   uint64(hs.array_buffer_memory_limit),
 	}
 }
 
@@ -176,8 +284,10 @@ func (i *Isolate) Dispose() {
 		return
 	}
 	if i.v8Lock != nil {
+		i.lockDepth = 1
 		i.Unlock()
 	}
+	i.cancelAsyncCalls()
 	C.IsolateDispose(i.ptr)
 	i.ptr = nil
 }
@@ -185,31 +295,141 @@ func (i *Isolate) Dispose() {
 // Acquires a V8 lock on the Isolate for this thread. This speeds up subsequent calls involving
 // Contexts, Values, Objects belonging to the Isolate.
 // You MUST call Unlock when done. (Disposing the Isolate will call Unlock for you.)
-// You MUST NOT make multiple calls to Lock; it's not recursive.
+// Lock is reentrant: the goroutine holding the lock may call it again, and must then call
+// Unlock as many times. Other goroutines calling Lock wait until it's released.
 func (i *Isolate) Lock() {
-	i.v8Mutex.Lock()
-	if i.v8Lock != nil {
-		panic("v8.Context.Lock called while already locked")
+	if i.IsLockedByCurrent() {
+		i.lockDepth++
+		return
 	}
+	i.v8Mutex.Lock()
 	// LockOSThread ensures that C calls from this goroutine will always be made on the same
 	// OS thread. This is absolutely necessary for making nested calls to v8::Locker (here and
 	// then in whatever other methods are called) so that they'll be treated as nested calls and
 	// not calls by different threads; otherwise the subsequent call will deadlock.
 	runtime.LockOSThread()
 	i.v8Lock = C.IsolateLock(i.ptr)
+	i.lockDepth = 1
+	i.setLockHeld(true)
 }
 
-// Releases the V8 locks acquired by Lock.
+// Releases the V8 locks acquired by Lock. It panics if the current goroutine doesn't hold them.
 func (i *Isolate) Unlock() {
-	if i.v8Lock == nil {
-		panic("v8.Context.Unlock called without first being locked")
+	if !i.IsLocked() {
+		panic("v8go: Isolate.Unlock called without first being locked")
+	} else if !i.IsLockedByCurrent() {
+		panic(fmt.Sprintf("v8go: Isolate.Unlock called on goroutine %d, which doesn't hold the lock",
+			goroutineID()))
+	}
+	if i.lockDepth--; i.lockDepth > 0 {
+		return
 	}
+	i.setLockHeld(false)
 	C.IsolateUnlock(i.v8Lock)
 	i.v8Lock = nil
 	runtime.UnlockOSThread()
 	i.v8Mutex.Unlock()
 }
 
+// IsLocked returns true if a goroutine holds the Isolate's lock.
+func (i *Isolate) IsLocked() bool {
+	return atomic.LoadInt32(&i.lockHeld) != 0
+}
+
+// IsLockedByCurrent returns true if the current goroutine holds the Isolate's lock.
+func (i *Isolate) IsLockedByCurrent() bool {
+	// The goroutine holding the lock is locked to its OS thread, so it's the only goroutine
+	// that can be running on the thread holding V8's lock:
+	return i.IsLocked() && C.IsolateIsLockedByCurrentThread(i.ptr) != 0
+}
+
+// Records whether the current goroutine holds the lock. Releasing the lock also releases the
+// goroutine's ownership, so that any goroutine may use the Isolate next.
+func (i *Isolate) setLockHeld(held bool) {
+	if held {
+		atomic.StoreInt32(&i.lockHeld, 1)
+		if checkOwnership {
+			goid := goroutineID()
+			atomic.StoreInt64(&i.lockOwner, goid)
+			atomic.StoreInt64(&i.lastUser, goid)
+		}
+	} else {
+		atomic.StoreInt32(&i.lockHeld, 0)
+		if checkOwnership {
+			atomic.StoreInt64(&i.lockOwner, 0)
+			atomic.StoreInt64(&i.lastUser, 0)
+		}
+	}
+}
+
+// WithUnlocked calls fn with the Isolate's lock released, so that other goroutines can use the
+// Isolate while fn blocks, for example on I/O, then waits to re-acquire the lock. It's meant to
+// be called by the goroutine holding the lock (see Lock), or by a FunctionCallback, which is
+// always called with the Isolate locked; otherwise it just calls fn.
+//
+// fn must not use the Isolate, or its Contexts and Values, since it doesn't hold the lock.
+// Values obtained before calling WithUnlocked can be used again after it returns.
+func (i *Isolate) WithUnlocked(fn func()) {
+	// The lock must be re-acquired on the same OS thread:
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	owned := i.IsLockedByCurrent()
+	var v8Lock C.WithIsolatePtr
+	var lockDepth int
+	if owned {
+		// Release the Lock() state too, so other goroutines can call Lock:
+		v8Lock, lockDepth = i.v8Lock, i.lockDepth
+		i.v8Lock, i.lockDepth = nil, 0
+		i.setLockHeld(false)
+		i.v8Mutex.Unlock()
+	}
+	unlocker := C.IsolateNewUnlocker(i.ptr)
+	defer func() {
+		if owned {
+			// Acquire the mutex before V8's lock, in the same order as Lock, to avoid deadlock:
+			i.v8Mutex.Lock()
+		}
+		C.UnlockerFree(unlocker)
+		if owned {
+			i.v8Lock, i.lockDepth = v8Lock, lockDepth
+			i.setLockHeld(true)
+		}
+	}()
+	fn()
+}
+
+// Panics if the Isolate is locked by a goroutine other than the current one, or, if it's not
+// locked, if another goroutine used it last without releasing it by calling Unlock. This only
+// checks anything in debug builds; see checkOwnership.
+func (i *Isolate) checkOwner() {
+	if checkOwnership {
+		goid := goroutineID()
+		if owner := atomic.LoadInt64(&i.lockOwner); owner != 0 {
+			if owner != goid {
+				panic(fmt.Sprintf("v8go: Isolate used on goroutine %d while it's locked by goroutine %d",
+					goid, owner))
+			}
+		} else if !atomic.CompareAndSwapInt64(&i.lastUser, 0, goid) {
+			if last := atomic.LoadInt64(&i.lastUser); last != goid {
+				panic(fmt.Sprintf("v8go: Isolate used on goroutine %d, but it was last used by goroutine %d; "+
+					"call Lock to hand it over", goid, last))
+			}
+		}
+	}
+}
+
+// SetAllowAtomicsWait controls whether JavaScript in this isolate may call `Atomics.wait`,
+// which blocks the calling thread until another thread (usually another isolate sharing a
+// SharedArrayBuffer) calls `Atomics.notify`. It's allowed by default.
+func (i *Isolate) SetAllowAtomicsWait(allow bool) {
+	var a C.Bool
+	if allow {
+		a = 1
+	}
+	C.IsolateSetAllowAtomicsWait(i.ptr, a)
+}
+
 // ThrowException schedules an exception to be thrown when returning to
 // JavaScript. When an exception has been scheduled it is illegal to invoke
 // any JavaScript operation; the caller must return immediately and only after
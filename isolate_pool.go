@@ -0,0 +1,302 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// IsolatePoolOptions are the settings of an IsolatePool. The zero value gives a pool with no
+// size limits whose Isolates are reused indefinitely.
+type IsolatePoolOptions struct {
+	// The number of Isolates created by NewIsolatePool. The pool replaces recycled Isolates
+	// so that it doesn't shrink below this size.
+	MinSize int
+	// The maximum number of Isolates in use at once; Get waits while this many are checked
+	// out. Zero means no limit.
+	MaxSize int
+
+	// The options for creating each Isolate.
+	IsolateOptions IsolateOptions
+	// If not nil, called once on each new Isolate, for example to compile scripts that every
+	// Context will run. It returns the global template of the Isolate's Contexts, or nil.
+	InitIsolate func(iso *Isolate) (*ObjectTemplate, error)
+	// If not nil, called on each fresh Context before Get returns it, for example to run
+	// scripts compiled by InitIsolate.
+	InitContext func(ctx *Context) error
+
+	// An Isolate is disposed, instead of returned to the pool, after this many uses.
+	// Zero means no limit.
+	MaxUses int
+	// An Isolate is disposed, instead of returned to the pool, when its used heap size is more
+	// than this fraction of its heap size limit, for example 0.8. Zero means no limit.
+	MaxHeapUsage float64
+}
+
+// IsolatePoolStats are the metrics of an IsolatePool.
+type IsolatePoolStats struct {
+	Idle     int // Number of Isolates in the pool waiting to be used
+	InUse    int // Number of Isolates checked out
+	Created  int // Number of Isolates created
+	Disposed int // Number of Isolates disposed
+
+	Checkouts int // Number of successful calls to Get
+	Waits     int // Number of calls to Get that had to wait for an Isolate to be released
+
+	RecycledForUses        int // Isolates disposed because they reached MaxUses
+	RecycledForHeap        int // Isolates disposed because they exceeded MaxHeapUsage
+	RecycledForTermination int // Isolates disposed because their execution was terminated
+	Discarded              int // Isolates disposed by PooledIsolate.Discard or failed checkouts
+}
+
+// IsolatePool is a pool of Isolates for handling many short tasks, such as HTTP requests,
+// without the cost of creating an Isolate for each one, or the danger of sharing one.
+// Get checks out an Isolate with a fresh Context, and PooledIsolate.Release returns it.
+// Unhealthy Isolates are disposed when they're released, instead of being reused.
+//
+// Pooled Isolates can't be created from a startup snapshot, since this package doesn't support
+// snapshots yet; use InitIsolate and InitContext to set them up instead.
+//
+// An IsolatePool is safe to use from multiple goroutines.
+type IsolatePool struct {
+	opts  IsolatePoolOptions
+	slots chan struct{} // Holds a token per Isolate in use, if MaxSize is set
+
+	mutex  sync.Mutex
+	idle   []*poolEntry
+	stats  IsolatePoolStats
+	closed bool
+}
+
+// An Isolate belonging to an IsolatePool.
+type poolEntry struct {
+	iso    *Isolate
+	global *ObjectTemplate // The global template returned by InitIsolate
+	uses   int
+}
+
+// PooledIsolate is an Isolate checked out of an IsolatePool, with a fresh Context.
+type PooledIsolate struct {
+	Isolate *Isolate
+	Context *Context
+
+	pool    *IsolatePool
+	entry   *poolEntry
+	discard bool
+}
+
+var errPoolClosed = errors.New("v8go: IsolatePool is closed")
+
+// NewIsolatePool creates an IsolatePool, along with its first MinSize Isolates.
+func NewIsolatePool(opts IsolatePoolOptions) (*IsolatePool, error) {
+	if opts.MaxSize > 0 && opts.MinSize > opts.MaxSize {
+		return nil, errors.New("v8go: IsolatePool MinSize is larger than MaxSize")
+	}
+	p := &IsolatePool{opts: opts}
+	if opts.MaxSize > 0 {
+		p.slots = make(chan struct{}, opts.MaxSize)
+	}
+	for i := 0; i < opts.MinSize; i++ {
+		entry, err := p.newEntry()
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.addIdle(entry)
+	}
+	return p, nil
+}
+
+// Get checks out an Isolate with a fresh Context, waiting if MaxSize Isolates are in use
+// until one is released or ctx is done. The Isolate is locked (see Isolate.Lock), so
+// Release must be called on the same goroutine.
+func (p *IsolatePool) Get(ctx context.Context) (*PooledIsolate, error) {
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			p.mutex.Lock()
+			p.stats.Waits++
+			p.mutex.Unlock()
+			select {
+			case p.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		p.releaseSlot()
+		return nil, errPoolClosed
+	}
+	var entry *poolEntry
+	if n := len(p.idle); n > 0 {
+		entry = p.idle[n-1]
+		p.idle = p.idle[:n-1]
+	}
+	p.mutex.Unlock()
+
+	if entry == nil {
+		var err error
+		if entry, err = p.newEntry(); err != nil {
+			p.releaseSlot()
+			return nil, err
+		}
+	}
+
+	entry.uses++
+	entry.iso.Lock()
+	pi := &PooledIsolate{
+		Isolate: entry.iso,
+		Context: NewContext(entry.iso, entry.global),
+		pool:    p,
+		entry:   entry,
+	}
+	p.mutex.Lock()
+	p.stats.InUse++
+	p.mutex.Unlock()
+	if p.opts.InitContext != nil {
+		if err := p.opts.InitContext(pi.Context); err != nil {
+			pi.Discard()
+			pi.Release()
+			return nil, err
+		}
+	}
+	p.mutex.Lock()
+	p.stats.Checkouts++
+	p.mutex.Unlock()
+	return pi, nil
+}
+
+// Stats returns the pool's metrics.
+func (p *IsolatePool) Stats() IsolatePoolStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	stats := p.stats
+	stats.Idle = len(p.idle)
+	return stats
+}
+
+// Close disposes the idle Isolates. Isolates in use are disposed when they're released.
+// Afterwards Get returns an error.
+func (p *IsolatePool) Close() {
+	p.mutex.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.stats.Disposed += len(idle)
+	p.mutex.Unlock()
+	for _, entry := range idle {
+		entry.iso.Dispose()
+	}
+}
+
+// Release closes the Context and returns the Isolate to the pool, or disposes it if it's
+// unhealthy or the pool is closed. It must be called on the goroutine that called Get, and
+// neither the Isolate nor the Context may be used afterwards.
+func (pi *PooledIsolate) Release() {
+	if pi.entry == nil {
+		return
+	}
+	p, entry := pi.pool, pi.entry
+	pi.entry = nil
+	pi.Context.Close()
+	terminated := entry.iso.takeTerminated()
+	heapTooLarge := p.heapTooLarge(entry.iso)
+	entry.iso.Unlock()
+
+	p.mutex.Lock()
+	p.stats.InUse--
+	reuse := false
+	switch {
+	case p.closed:
+	case pi.discard:
+		p.stats.Discarded++
+	case terminated:
+		p.stats.RecycledForTermination++
+	case p.opts.MaxUses > 0 && entry.uses >= p.opts.MaxUses:
+		p.stats.RecycledForUses++
+	case heapTooLarge:
+		p.stats.RecycledForHeap++
+	default:
+		reuse = true
+		p.idle = append(p.idle, entry)
+	}
+	var replace bool
+	if !reuse {
+		p.stats.Disposed++
+		replace = !p.closed && p.stats.Created-p.stats.Disposed < p.opts.MinSize
+	}
+	p.mutex.Unlock()
+
+	if !reuse {
+		entry.iso.Dispose()
+		if replace {
+			if newEntry, err := p.newEntry(); err == nil {
+				p.addIdle(newEntry)
+			}
+		}
+	}
+	p.releaseSlot()
+}
+
+// Discard makes Release dispose the Isolate instead of returning it to the pool. Call it
+// when the Isolate may be in a bad state, for example after calling TerminateExecution.
+func (pi *PooledIsolate) Discard() {
+	pi.discard = true
+}
+
+// Creates an Isolate and calls InitIsolate on it.
+func (p *IsolatePool) newEntry() (*poolEntry, error) {
+	iso := NewIsolateWithOptions(p.opts.IsolateOptions)
+	entry := &poolEntry{iso: iso}
+	if p.opts.InitIsolate != nil {
+		global, err := p.opts.InitIsolate(iso)
+		if err != nil {
+			iso.Dispose()
+			return nil, err
+		}
+		entry.global = global
+	}
+	p.mutex.Lock()
+	p.stats.Created++
+	p.mutex.Unlock()
+	return entry, nil
+}
+
+// Adds a new Isolate to the idle ones, or disposes it if the pool has been closed meanwhile.
+func (p *IsolatePool) addIdle(entry *poolEntry) {
+	p.mutex.Lock()
+	closed := p.closed
+	if closed {
+		p.stats.Disposed++
+	} else {
+		p.idle = append(p.idle, entry)
+	}
+	p.mutex.Unlock()
+	if closed {
+		entry.iso.Dispose()
+	}
+}
+
+// Returns true if the Isolate's used heap exceeds MaxHeapUsage.
+func (p *IsolatePool) heapTooLarge(iso *Isolate) bool {
+	if p.opts.MaxHeapUsage <= 0 {
+		return false
+	}
+	hs := iso.GetHeapStatistics()
+	return hs.HeapSizeLimit > 0 && float64(hs.UsedHeapSize) > p.opts.MaxHeapUsage*float64(hs.HeapSizeLimit)
+}
+
+func (p *IsolatePool) releaseSlot() {
+	if p.slots != nil {
+		<-p.slots
+	}
+}
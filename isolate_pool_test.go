@@ -0,0 +1,197 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestIsolatePool(t *testing.T) {
+	t.Parallel()
+
+	pool, err := v8.NewIsolatePool(v8.IsolatePoolOptions{
+		MinSize: 1,
+		MaxSize: 2,
+		InitIsolate: func(iso *v8.Isolate) (*v8.ObjectTemplate, error) {
+			global := v8.NewObjectTemplate(iso)
+			return global, global.Set("answer", int32(42))
+		},
+	})
+	fatalIf(t, err)
+	defer pool.Close()
+	if stats := pool.Stats(); stats.Created != 1 || stats.Idle != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	pi, err := pool.Get(context.Background())
+	fatalIf(t, err)
+	val, err := pi.Context.RunScript("globalThis.leftover = 1; answer", "pool.js")
+	fatalIf(t, err)
+	if val.Int32() != 42 {
+		t.Errorf("expected 42, got %v", val)
+	}
+	iso := pi.Isolate
+	pi.Release()
+
+	// The Isolate is reused, with a fresh Context:
+	pi, err = pool.Get(context.Background())
+	fatalIf(t, err)
+	if pi.Isolate != iso {
+		t.Error("expected the Isolate to be reused")
+	}
+	val, err = pi.Context.RunScript("typeof leftover", "pool.js")
+	fatalIf(t, err)
+	if val.String() != "undefined" {
+		t.Errorf("expected a fresh Context, got leftover of type %s", val)
+	}
+	pi.Release()
+
+	if stats := pool.Stats(); stats.Checkouts != 2 || stats.InUse != 0 || stats.Created != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	pool.Close()
+	if _, err := pool.Get(context.Background()); err == nil {
+		t.Error("expected an error from a closed pool")
+	}
+}
+
+func TestIsolatePoolMaxSize(t *testing.T) {
+	t.Parallel()
+
+	pool, err := v8.NewIsolatePool(v8.IsolatePoolOptions{MaxSize: 1})
+	fatalIf(t, err)
+	defer pool.Close()
+
+	pi, err := pool.Get(context.Background())
+	fatalIf(t, err)
+	defer pi.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if stats := pool.Stats(); stats.Waits != 1 {
+		t.Errorf("expected 1 wait, got %d", stats.Waits)
+	}
+}
+
+func TestIsolatePoolRecycling(t *testing.T) {
+	t.Parallel()
+
+	pool, err := v8.NewIsolatePool(v8.IsolatePoolOptions{MinSize: 1, MaxUses: 2})
+	fatalIf(t, err)
+	defer pool.Close()
+
+	var isolates []*v8.Isolate
+	for i := 0; i < 3; i++ {
+		pi, err := pool.Get(context.Background())
+		fatalIf(t, err)
+		isolates = append(isolates, pi.Isolate)
+		pi.Release()
+	}
+	if isolates[0] != isolates[1] || isolates[1] == isolates[2] {
+		t.Error("expected the Isolate to be recycled after 2 uses")
+	}
+
+	pi, err := pool.Get(context.Background())
+	fatalIf(t, err)
+	pi.Discard()
+	pi.Release()
+
+	stats := pool.Stats()
+	if stats.RecycledForUses != 1 || stats.Discarded != 1 || stats.Idle != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.Created-stats.Disposed != 1 {
+		t.Errorf("expected the pool to keep its minimum size, stats %+v", stats)
+	}
+}
+
+func TestIsolatePoolTerminationRecycling(t *testing.T) {
+	t.Parallel()
+
+	pool, err := v8.NewIsolatePool(v8.IsolatePoolOptions{
+		MinSize: 1,
+		InitIsolate: func(iso *v8.Isolate) (*v8.ObjectTemplate, error) {
+			global := v8.NewObjectTemplate(iso)
+			stop := v8.NewFunctionTemplate(iso, func(info *v8.FunctionCallbackInfo) *v8.Value {
+				iso.TerminateExecution()
+				return nil
+			})
+			return global, global.Set("stop", stop)
+		},
+	})
+	fatalIf(t, err)
+	defer pool.Close()
+
+	pi, err := pool.Get(context.Background())
+	fatalIf(t, err)
+	iso := pi.Isolate
+	if _, err := pi.Context.RunScript("stop(); while (true) {}", "stop.js"); err == nil {
+		t.Error("expected the script to be terminated")
+	}
+	pi.Release()
+
+	pi, err = pool.Get(context.Background())
+	fatalIf(t, err)
+	if pi.Isolate == iso {
+		t.Error("expected the terminated Isolate to be recycled")
+	}
+	pi.Release()
+	if stats := pool.Stats(); stats.RecycledForTermination != 1 || stats.Idle != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestIsolatePoolHeapRecycling(t *testing.T) {
+	t.Parallel()
+
+	// Any Isolate uses more than this fraction of its heap limit:
+	pool, err := v8.NewIsolatePool(v8.IsolatePoolOptions{MinSize: 1, MaxHeapUsage: 1e-9})
+	fatalIf(t, err)
+	defer pool.Close()
+
+	pi, err := pool.Get(context.Background())
+	fatalIf(t, err)
+	iso := pi.Isolate
+	pi.Release()
+
+	pi, err = pool.Get(context.Background())
+	fatalIf(t, err)
+	if pi.Isolate == iso {
+		t.Error("expected the Isolate to be recycled")
+	}
+	pi.Release()
+	if stats := pool.Stats(); stats.RecycledForHeap != 2 || stats.Idle != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestIsolatePoolInitContextError(t *testing.T) {
+	t.Parallel()
+
+	pool, err := v8.NewIsolatePool(v8.IsolatePoolOptions{
+		InitContext: func(ctx *v8.Context) error {
+			_, err := ctx.RunScript("throw new Error('oops')", "init.js")
+			return err
+		},
+	})
+	fatalIf(t, err)
+	defer pool.Close()
+
+	if _, err := pool.Get(context.Background()); err == nil {
+		t.Error("expected an error from InitContext")
+	}
+	if stats := pool.Stats(); stats.Checkouts != 0 || stats.InUse != 0 || stats.Discarded != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
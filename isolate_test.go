@@ -159,6 +159,47 @@ func TestIsolateGetHeapStatistics(t *testing.T) {
 	}
 }
 
+func TestIsolateArrayBufferMemoryLimit(t *testing.T) {
+	t.Parallel()
+	const limit = 1 << 20
+	iso := v8.NewIsolateWithOptions(v8.IsolateOptions{MaxArrayBufferMemory: limit})
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	hs := iso.GetHeapStatistics()
+	if hs.ArrayBufferMemoryLimit != limit {
+		t.Errorf("unexpected ArrayBufferMemoryLimit %d", hs.ArrayBufferMemoryLimit)
+	}
+	before := hs.ArrayBufferMemory
+
+	_, err := ctx.RunScript("var buf = new ArrayBuffer(500000)", "")
+	fatalIf(t, err)
+	if used := iso.GetHeapStatistics().ArrayBufferMemory; used < before+500000 {
+		t.Errorf("ArrayBuffer memory not accounted for: %d bytes", used)
+	}
+
+	_, err = ctx.RunScript("new ArrayBuffer(600000)", "")
+	if err == nil || !strings.HasPrefix(err.Error(), "RangeError") {
+		t.Errorf("expected RangeError from JS allocation over the limit, got %v", err)
+	}
+	_, err = v8.NewArrayBuffer(ctx, make([]byte, 600000))
+	if err == nil || !strings.HasPrefix(err.Error(), "RangeError") {
+		t.Errorf("expected RangeError from Go allocation over the limit, got %v", err)
+	}
+
+	// Without a limit, usage is still reported:
+	iso2 := v8.NewIsolate()
+	defer iso2.Dispose()
+	ctx2 := v8.NewContext(iso2)
+	defer ctx2.Close()
+	_, err = v8.NewArrayBuffer(ctx2, make([]byte, 600000))
+	fatalIf(t, err)
+	if hs := iso2.GetHeapStatistics(); hs.ArrayBufferMemoryLimit != 0 || hs.ArrayBufferMemory < 600000 {
+		t.Errorf("unexpected statistics %+v", hs)
+	}
+}
+
 func TestCallbackRegistry(t *testing.T) {
 	t.Parallel()
 
@@ -199,6 +240,65 @@ func TestIsolateDispose(t *testing.T) {
 	}
 }
 
+func TestIsolateLockReentrant(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	if iso.IsLocked() || iso.IsLockedByCurrent() {
+		t.Error("expected a new Isolate to be unlocked")
+	}
+
+	iso.Lock()
+	iso.Lock()
+	if !iso.IsLocked() || !iso.IsLockedByCurrent() {
+		t.Error("expected the Isolate to be locked by this goroutine")
+	}
+	ctx := v8.NewContext(iso)
+	if _, err := ctx.RunScript("1 + 1", "lock.js"); err != nil {
+		t.Error(err)
+	}
+	ctx.Close()
+
+	// Another goroutine sees the lock, and waits for it:
+	locked := make(chan bool)
+	released := make(chan struct{})
+	go func() {
+		defer close(released)
+		locked <- iso.IsLocked() && !iso.IsLockedByCurrent()
+		iso.Lock()
+		iso.Unlock()
+	}()
+	if !<-locked {
+		t.Error("expected another goroutine to see the Isolate locked by this one")
+	}
+
+	iso.Unlock()
+	if !iso.IsLockedByCurrent() {
+		t.Error("expected the Isolate to stay locked until the outer Unlock")
+	}
+	iso.Unlock()
+	// Wait until the other goroutine has released the lock before disposing the Isolate:
+	<-released
+}
+
+func TestIsolateUnlockByOtherGoroutine(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	iso.Lock()
+	defer iso.Dispose()
+
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		iso.Unlock()
+	}()
+	if <-panicked == nil {
+		t.Error("expected Unlock by a goroutine not holding the lock to panic")
+	}
+}
+
 func TestIsolateThrowException(t *testing.T) {
 	t.Parallel()
 	iso := v8.NewIsolate()
@@ -0,0 +1,545 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Iterate calls the callback with each value produced by a JavaScript iterable -- an Array,
+// string, Map, Set, generator, or any object with a `Symbol.iterator` method -- as a
+// `for...of` loop would. If the callback returns an error, iteration stops (calling the
+// iterator's `return` method, as `break` would) and Iterate returns that error.
+func (v *Value) Iterate(callback func(*Value) error) error {
+	iter, err := v.getIterator(false)
+	if err != nil {
+		return err
+	}
+	for {
+		result, err := iter.step(nil)
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			return nil
+		}
+		if err = callback(result); err != nil {
+			iter.close(nil)
+			return err
+		}
+	}
+}
+
+// IterateAsync is like Iterate, but consumes a JavaScript async iterable, as a `for await...of`
+// loop would: it uses the `Symbol.asyncIterator` method if there is one, otherwise the
+// `Symbol.iterator` method, and awaits the promises it produces. Awaiting runs the Context's
+// microtask queue; if a promise is still pending after that, IterateAsync settles async Go
+// calls (see NewAsyncFunctionTemplate) as they finish, until the promise settles or goCtx is
+// done, in which case it returns goCtx.Err().
+func (v *Value) IterateAsync(goCtx context.Context, callback func(*Value) error) error {
+	iter, err := v.getIterator(true)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := goCtx.Err(); err != nil {
+			iter.close(goCtx)
+			return err
+		}
+		result, err := iter.step(goCtx)
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			return nil
+		}
+		if err = callback(result); err != nil {
+			iter.close(goCtx)
+			return err
+		}
+	}
+}
+
+// jsIterator is a JavaScript iterator being consumed by Iterate or IterateAsync.
+type jsIterator struct {
+	obj   *Object
+	next  *Function
+	async bool // True if it's an async iterator, whose methods return promises
+}
+
+func (v *Value) getIterator(async bool) (*jsIterator, error) {
+	if v.IsNullOrUndefined() {
+		return nil, errors.New("v8go: value is not iterable")
+	}
+	iso := v.ctx.iso
+	obj := v.Object()
+	var method *Value
+	var err error
+	isAsync := false
+	if async {
+		if method, err = obj.GetKey(SymbolAsyncIterator(iso).Value); err != nil {
+			return nil, err
+		}
+		isAsync = method.IsFunction()
+	}
+	if !isAsync {
+		if method, err = obj.GetKey(SymbolIterator(iso).Value); err != nil {
+			return nil, err
+		}
+		if !method.IsFunction() {
+			return nil, errors.New("v8go: value is not iterable")
+		}
+	}
+	fn, _ := method.AsFunction()
+	iterVal, err := fn.Call(v)
+	if err != nil {
+		return nil, err
+	}
+	iterObj, err := iterVal.AsObject()
+	if err != nil {
+		return nil, errors.New("v8go: iterator is not an object")
+	}
+	nextVal, err := iterObj.Get("next")
+	if err != nil {
+		return nil, err
+	}
+	next, err := nextVal.AsFunction()
+	if err != nil {
+		return nil, errors.New("v8go: iterator has no next method")
+	}
+	return &jsIterator{obj: iterObj, next: next, async: isAsync}, nil
+}
+
+// Advances the iterator, returning the next value or nil at the end.
+// If goCtx is non-nil, promises are awaited.
+func (it *jsIterator) step(goCtx context.Context) (*Value, error) {
+	result, err := it.next.Call(it.obj)
+	if err != nil {
+		return nil, err
+	}
+	if it.async {
+		if result, err = awaitValue(goCtx, result); err != nil {
+			return nil, err
+		}
+	}
+	resultObj, err := result.AsObject()
+	if err != nil {
+		return nil, errors.New("v8go: iterator result is not an object")
+	}
+	done, err := resultObj.Get("done")
+	if err != nil {
+		return nil, err
+	}
+	if done.Boolean() {
+		return nil, nil
+	}
+	val, err := resultObj.Get("value")
+	if err != nil {
+		return nil, err
+	}
+	if goCtx != nil && !it.async {
+		// `for await` awaits the values of a sync iterator:
+		return awaitValue(goCtx, val)
+	}
+	return val, nil
+}
+
+// Calls the iterator's `return` method, if any, to let it clean up after an early exit.
+func (it *jsIterator) close(goCtx context.Context) {
+	ret, err := it.obj.Get("return")
+	if err != nil || !ret.IsFunction() {
+		return
+	}
+	fn, _ := ret.AsFunction()
+	if result, err := fn.Call(it.obj); err == nil && it.async && goCtx != nil {
+		_, _ = awaitValue(goCtx, result)
+	}
+}
+
+// Returns the fulfilled value of a promise, running microtasks, and settling async Go calls
+// as they finish, until it settles. A value that isn't a promise is returned as-is.
+func awaitValue(goCtx context.Context, val *Value) (*Value, error) {
+	if !val.IsPromise() {
+		return val, nil
+	}
+	p, _ := val.AsPromise()
+	iso := val.ctx.iso
+	val.ctx.PerformMicrotaskCheckpoint()
+	for {
+		switch p.State() {
+		case Fulfilled:
+			return p.Result(), nil
+		case Rejected:
+			return nil, rejectionError(p.Result())
+		}
+		// Only a finished async call can settle the promise now:
+		select {
+		case <-iso.asyncReady:
+			iso.SettleAsyncCalls()
+		case <-goCtx.Done():
+			return nil, goCtx.Err()
+		}
+	}
+}
+
+// Converts the reason a promise was rejected into a JSError.
+func rejectionError(reason *Value) error {
+	err := &JSError{Message: reason.String()}
+	if reason.IsObject() {
+		if stack, _ := reason.Object().Get("stack"); stack != nil && stack.IsString() {
+			err.StackTrace = stack.String()
+		}
+	}
+	return err
+}
+
+// NewIterable creates a JavaScript iterable whose values come from a Go source, which may be:
+//   - a channel, which is read until it's closed;
+//   - a sequence function `func(yield func(T) bool)`, like Go 1.23's `iter.Seq`;
+//   - a sequence function `func(yield func(K, V) bool)`, like `iter.Seq2`, whose pairs are
+//     produced as two-element arrays.
+//
+// The values are converted with Context.NewValue. Reading from a channel blocks JavaScript
+// until a value is available. A sequence function runs on its own goroutine, which exits when
+// the sequence ends, when JavaScript stops iterating early (e.g. with `break`), or when the
+// iterable is garbage-collected or its Context is closed.
+func NewIterable(ctx *Context, source interface{}) (*Object, error) {
+	return newGoIterable(ctx, source, false)
+}
+
+// NewAsyncIterable is like NewIterable, but creates an async iterable for use with
+// `for await...of`, whose `next` method returns a Promise. Values are read from the source on
+// another goroutine, so JavaScript isn't blocked while waiting for them; like the results of
+// async Go functions (see NewAsyncFunctionTemplate), they're delivered by
+// Isolate.SettleAsyncCalls or Isolate.WaitAsyncCalls.
+func NewAsyncIterable(ctx *Context, source interface{}) (*Object, error) {
+	return newGoIterable(ctx, source, true)
+}
+
+// goIterator is the Go side of an iterable created by NewIterable or NewAsyncIterable.
+type goIterator struct {
+	next  func() (interface{}, bool, error) // Returns the next value, or false at the end
+	stop  func()                            // Ends the iteration early, without blocking
+	async bool
+	last  chan struct{} // Closed when the latest async `next` call has finished
+}
+
+// The result of an async `next` call, which SettleAsyncCalls turns into an iterator result.
+type iteratorStep struct {
+	val  interface{}
+	done bool
+	err  error
+}
+
+var goIteratorSeq int32
+
+func newGoIterable(ctx *Context, source interface{}, async bool) (*Object, error) {
+	next, stop, err := pullFrom(source)
+	if err != nil {
+		return nil, err
+	}
+	iso := ctx.iso
+	obj, err := iso.iteratorTemplate().NewInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id := atomic.AddInt32(&goIteratorSeq, 1)
+	if err = obj.SetInternalField(0, id); err != nil {
+		return nil, err
+	}
+	// The object is its own iterator:
+	key := SymbolIterator(iso)
+	if async {
+		key = SymbolAsyncIterator(iso)
+	}
+	if err = obj.SetKey(key.Value, iso.iteratorSelfTmpl.GetFunction(ctx)); err != nil {
+		return nil, err
+	}
+	// The source is stopped when the iterable is garbage-collected:
+	if ctx.iterators == nil {
+		ctx.iterators = map[int32]*goIterator{}
+	}
+	ctx.iterators[id] = &goIterator{next: next, stop: stop, async: async}
+	ctx.addGoRef(obj, iteratorRef, id)
+	return obj, nil
+}
+
+// Returns the ObjectTemplate for iterables created by NewIterable, creating it on first use.
+// The goIterator's ID is stored in internal field 0.
+func (i *Isolate) iteratorTemplate() *ObjectTemplate {
+	if i.iteratorTmpl == nil {
+		tmpl := NewObjectTemplate(i)
+		tmpl.SetInternalFieldCount(1)
+		tmpl.Set("next", NewFunctionTemplate(i, func(info *FunctionCallbackInfo) *Value {
+			ctx := info.Context()
+			id, it := goIteratorFromThis(info)
+			if it != nil && it.async {
+				return it.nextAsync(ctx, id)
+			}
+			var val interface{}
+			ok := false
+			var err error
+			if it != nil {
+				if val, ok, err = it.next(); !ok {
+					delete(ctx.iterators, id)
+				}
+			}
+			var result *Value
+			if err == nil {
+				result, err = iteratorResult(ctx, val, !ok)
+			}
+			if err != nil {
+				return throwJSError(ctx, err)
+			}
+			return result
+		}))
+		tmpl.Set("return", NewFunctionTemplate(i, func(info *FunctionCallbackInfo) *Value {
+			ctx := info.Context()
+			id, it := goIteratorFromThis(info)
+			if it != nil {
+				delete(ctx.iterators, id)
+				it.stop()
+			}
+			result, err := iteratorResult(ctx, nil, true)
+			if it != nil && it.async {
+				return promiseFor(ctx, result, err)
+			}
+			return result
+		}))
+		i.iteratorTmpl = tmpl
+		i.iteratorSelfTmpl = NewFunctionTemplate(i, func(info *FunctionCallbackInfo) *Value {
+			return info.This().Value
+		})
+	}
+	return i.iteratorTmpl
+}
+
+// Implements `next` for an async iterable: pulls the next value on a new goroutine, and returns
+// a Promise of the iterator result. Calls are chained, so that they pull values and settle their
+// Promises in order even if JavaScript doesn't wait for one before making the next.
+func (it *goIterator) nextAsync(ctx *Context, id int32) *Value {
+	resolver, err := NewPromiseResolver(ctx)
+	if err != nil {
+		return throwJSError(ctx, err)
+	}
+	call := &asyncCall{ctx: ctx, resolver: resolver}
+	call.convert = func(ctx *Context, result interface{}) (*Value, error) {
+		step := result.(iteratorStep)
+		if step.done {
+			delete(ctx.iterators, id)
+		}
+		if step.err != nil {
+			return nil, step.err
+		}
+		return iteratorResult(ctx, step.val, step.done)
+	}
+
+	iso := ctx.iso
+	prev, finished := it.last, make(chan struct{})
+	it.last = finished
+	iso.startAsyncCall()
+	go func() {
+		if prev != nil {
+			<-prev
+		}
+		val, ok, err := it.next()
+		call.result = iteratorStep{val: val, done: !ok, err: err}
+		iso.finishAsyncCall(call)
+		close(finished)
+	}()
+	return resolver.GetPromise().Value
+}
+
+// Finds the goIterator whose JS object is the receiver of a callback. Returns nil if the
+// iteration has finished.
+func goIteratorFromThis(info *FunctionCallbackInfo) (int32, *goIterator) {
+	this := info.This()
+	if this == nil || this.InternalFieldCount() != 1 {
+		return 0, nil
+	}
+	id := this.GetInternalField(0)
+	if !id.IsInt32() {
+		return 0, nil
+	}
+	if it, ok := info.Context().iterators[id.Int32()]; ok {
+		return id.Int32(), it
+	}
+	return 0, nil
+}
+
+// Creates an iterator result object `{value, done}`.
+func iteratorResult(ctx *Context, val interface{}, done bool) (*Value, error) {
+	result := ctx.NewObject()
+	if done {
+		val = Undefined(ctx.iso)
+	} else if pair, ok := val.(seqPair); ok {
+		arr := ctx.NewArray(2)
+		for i, item := range pair {
+			if err := arr.SetIdx(uint32(i), item); err != nil {
+				return nil, err
+			}
+		}
+		val = arr
+	}
+	if err := result.Set("value", val); err != nil {
+		return nil, err
+	}
+	if err := result.Set("done", done); err != nil {
+		return nil, err
+	}
+	return result.Value, nil
+}
+
+// Returns a Promise that's fulfilled with the value, or rejected with the error.
+func promiseFor(ctx *Context, val *Value, err error) *Value {
+	resolver, rerr := NewPromiseResolver(ctx)
+	if rerr != nil {
+		return throwJSError(ctx, rerr)
+	}
+	if err != nil {
+		resolver.Reject(jsErrorValue(ctx, err))
+	} else {
+		resolver.Resolve(val)
+	}
+	return resolver.GetPromise().Value
+}
+
+// Returns functions that pull values one at a time from a channel or sequence function.
+// The stop function doesn't wait for anything, since it's called during garbage collection;
+// it just makes a pending or later call to next return false.
+func pullFrom(source interface{}) (next func() (interface{}, bool, error), stop func(), err error) {
+	rv := reflect.ValueOf(source)
+	switch rv.Kind() {
+	case reflect.Chan:
+		if rv.Type().ChanDir()&reflect.RecvDir != 0 {
+			quit, stop := newQuit()
+			cases := []reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(quit)},
+				{Dir: reflect.SelectRecv, Chan: rv},
+			}
+			next = func() (interface{}, bool, error) {
+				if isClosed(quit) {
+					return nil, false, nil
+				}
+				chosen, val, ok := reflect.Select(cases)
+				if chosen == 0 || !ok {
+					return nil, false, nil
+				}
+				return val.Interface(), true, nil
+			}
+			return next, stop, nil
+		}
+	case reflect.Func:
+		if t := rv.Type(); t.NumIn() == 1 && t.NumOut() == 0 {
+			if y := t.In(0); y.Kind() == reflect.Func && (y.NumIn() == 1 || y.NumIn() == 2) &&
+				y.NumOut() == 1 && y.Out(0).Kind() == reflect.Bool {
+				next, stop = pullFromSeq(rv)
+				return next, stop, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("v8go: iterable source must be a channel or sequence function, not %T", source)
+}
+
+// Returns a channel, and a function that closes it, which may be called more than once.
+func newQuit() (quit chan struct{}, stop func()) {
+	quit = make(chan struct{})
+	var once sync.Once
+	return quit, func() { once.Do(func() { close(quit) }) }
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// A key-value pair from an `iter.Seq2`-style function, which becomes a two-element Array.
+type seqPair [2]interface{}
+
+// A value, or the end of the sequence, handed from a sequence function's goroutine to next.
+type seqItem struct {
+	val  interface{}
+	done bool
+	err  error
+}
+
+// Converts a push-style sequence function into a pull-style iterator, by running it on its
+// own goroutine and handing off each value through a channel. Once stopped, yield returns false,
+// so the sequence function can return and the goroutine exit.
+func pullFromSeq(seq reflect.Value) (next func() (interface{}, bool, error), stop func()) {
+	requests := make(chan struct{}) // Asks the sequence for its next value
+	items := make(chan seqItem)
+	quit, stop := newQuit()
+	started, finished := false, false
+
+	yield := reflect.MakeFunc(seq.Type().In(0), func(args []reflect.Value) []reflect.Value {
+		var val interface{}
+		if len(args) == 1 {
+			val = args[0].Interface()
+		} else {
+			val = seqPair{args[0].Interface(), args[1].Interface()}
+		}
+		more := false
+		select {
+		case items <- seqItem{val: val}:
+			select {
+			case <-requests:
+				more = true
+			case <-quit:
+			}
+		case <-quit:
+		}
+		return []reflect.Value{reflect.ValueOf(more)}
+	})
+	run := func() {
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("v8go: iterable source panicked: %v", r)
+			}
+			select {
+			case items <- seqItem{done: true, err: err}:
+			case <-quit:
+			}
+		}()
+		seq.Call([]reflect.Value{yield})
+	}
+
+	next = func() (interface{}, bool, error) {
+		if finished || isClosed(quit) {
+			return nil, false, nil
+		}
+		if !started {
+			started = true
+			go run()
+		} else {
+			select {
+			case requests <- struct{}{}:
+			case <-quit:
+				return nil, false, nil
+			}
+		}
+		select {
+		case item := <-items:
+			if item.done {
+				finished = true
+				return nil, false, item.err
+			}
+			return item.val, true, nil
+		case <-quit:
+			return nil, false, nil
+		}
+	}
+	return next, stop
+}
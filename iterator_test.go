@@ -0,0 +1,304 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+// Collects the values of a JS iterable as strings.
+func iterateStrings(t *testing.T, val *v8.Value) string {
+	t.Helper()
+	var items []string
+	fatalIf(t, val.Iterate(func(v *v8.Value) error {
+		items = append(items, v.String())
+		return nil
+	}))
+	return strings.Join(items, ",")
+}
+
+func TestIterate(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	tests := [...]struct {
+		source   string
+		expected string
+	}{
+		{"[1, 2, 3]", "1,2,3"},
+		{"'héllo'", "h,é,l,l,o"},
+		{"new Map([['a', 1], ['b', 2]])", "a,1,b,2"},
+		{"new Set([3, 2, 1]).values()", "3,2,1"},
+		{"(function*() { yield 'x'; yield 'y'; })()", "x,y"},
+		{"[]", ""},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.source, "")
+		fatalIf(t, err)
+		if s := iterateStrings(t, val); s != tt.expected {
+			t.Errorf("iterating %s: expected %q, got %q", tt.source, tt.expected, s)
+		}
+	}
+
+	// Stopping early calls the iterator's `return` method:
+	gen, err := ctx.RunScript(`
+		var cleanedUp = false;
+		(function*() { try { yield 1; yield 2; } finally { cleanedUp = true; } })()`, "")
+	fatalIf(t, err)
+	stop := errors.New("stop")
+	if err := gen.Iterate(func(v *v8.Value) error { return stop }); err != stop {
+		t.Errorf("expected callback's error, got %v", err)
+	}
+	cleanedUp, err := ctx.RunScript("cleanedUp", "")
+	fatalIf(t, err)
+	if !cleanedUp.Boolean() {
+		t.Error("expected iterator to be closed")
+	}
+
+	// Exceptions propagate:
+	bad, err := ctx.RunScript("(function*() { yield 1; throw new Error('oops'); })()", "")
+	fatalIf(t, err)
+	err = bad.Iterate(func(v *v8.Value) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("expected exception from iterator, got %v", err)
+	}
+
+	for _, source := range []string{"42", "({})", "undefined"} {
+		val, err := ctx.RunScript(source, "")
+		fatalIf(t, err)
+		if err := val.Iterate(func(v *v8.Value) error { return nil }); err == nil {
+			t.Errorf("expected %s not to be iterable", source)
+		}
+	}
+}
+
+func TestIterateAsync(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+	goCtx := context.Background()
+
+	tests := [...]struct {
+		source   string
+		expected string
+	}{
+		{"(async function*() { yield 1; await null; yield 2; })()", "1,2"},
+		{"[Promise.resolve('a'), 'b']", "a,b"},
+	}
+	for _, tt := range tests {
+		val, err := ctx.RunScript(tt.source, "")
+		fatalIf(t, err)
+		var items []string
+		fatalIf(t, val.IterateAsync(goCtx, func(v *v8.Value) error {
+			items = append(items, v.String())
+			return nil
+		}))
+		if s := strings.Join(items, ","); s != tt.expected {
+			t.Errorf("iterating %s: expected %q, got %q", tt.source, tt.expected, s)
+		}
+	}
+
+	rejects, err := ctx.RunScript("(async function*() { yield 1; throw new RangeError('nope'); })()", "")
+	fatalIf(t, err)
+	err = rejects.IterateAsync(goCtx, func(v *v8.Value) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "RangeError: nope") {
+		t.Errorf("expected rejection error, got %v", err)
+	}
+
+	// A promise that never settles times out:
+	never, err := ctx.RunScript("(async function*() { await new Promise(() => {}); })()", "")
+	fatalIf(t, err)
+	timeout, cancel := context.WithTimeout(goCtx, 20*time.Millisecond)
+	defer cancel()
+	err = never.IterateAsync(timeout, func(v *v8.Value) error { return nil })
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestIterateAsyncAwaitsGoCalls(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	later := v8.NewAsyncFunctionTemplate(iso, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		time.Sleep(10 * time.Millisecond)
+		return args[0], nil
+	})
+	fatalIf(t, ctx.Global().Set("later", later.GetFunction(ctx)))
+	val, err := ctx.RunScript("(async function*() { yield await later('a'); yield later('b'); })()", "")
+	fatalIf(t, err)
+	var items []string
+	fatalIf(t, val.IterateAsync(context.Background(), func(v *v8.Value) error {
+		items = append(items, v.String())
+		return nil
+	}))
+	if s := strings.Join(items, ","); s != "a,b" {
+		t.Errorf("unexpected items %q", s)
+	}
+}
+
+func TestNewIterable(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	ch := make(chan string, 3)
+	ch <- "a"
+	ch <- "b"
+	close(ch)
+	fromChan, err := v8.NewIterable(ctx, ch)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("fromChan", fromChan))
+	val, err := ctx.RunScript("[...fromChan].join()", "")
+	fatalIf(t, err)
+	if val.String() != "a,b" {
+		t.Errorf("unexpected values from channel: %v", val)
+	}
+
+	seqDone := make(chan struct{})
+	seq := func(yield func(int) bool) {
+		defer close(seqDone)
+		for i := 1; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	fromSeq, err := v8.NewIterable(ctx, seq)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("fromSeq", fromSeq))
+	val, err = ctx.RunScript("let sum = 0; for (const n of fromSeq) { if (n > 4) break; sum += n; } sum", "")
+	fatalIf(t, err)
+	if val.Int32() != 10 {
+		t.Errorf("unexpected sum %v", val)
+	}
+	select {
+	case <-seqDone:
+	case <-time.After(5 * time.Second):
+		t.Error("sequence function didn't stop after break")
+	}
+
+	pairs := func(yield func(string, int) bool) {
+		_ = yield("x", 1) && yield("y", 2)
+	}
+	fromPairs, err := v8.NewIterable(ctx, pairs)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("fromPairs", fromPairs))
+	val, err = ctx.RunScript("JSON.stringify(Object.fromEntries(fromPairs))", "")
+	fatalIf(t, err)
+	if s := val.String(); s != `{"x":1,"y":2}` {
+		t.Errorf("unexpected entries %s", s)
+	}
+
+	// Go values can be iterated from Go too:
+	if s := iterateStrings(t, fromPairs.Value); s != "" {
+		t.Errorf("expected exhausted iterable, got %q", s)
+	}
+
+	if _, err := v8.NewIterable(ctx, 42); err == nil {
+		t.Error("expected error for unsupported source")
+	}
+	if _, err := v8.NewIterable(ctx, make(chan<- int)); err == nil {
+		t.Error("expected error for send-only channel")
+	}
+}
+
+func TestNewAsyncIterable(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	ch := make(chan int)
+	iterable, err := v8.NewAsyncIterable(ctx, ch)
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("numbers", iterable))
+	// Nothing is sent yet, so this would hang if `next` blocked JavaScript:
+	val, err := ctx.RunScript("(async () => { let sum = 0; for await (const n of numbers) sum += n; return sum; })()", "")
+	fatalIf(t, err)
+	promise, err := val.AsPromise()
+	fatalIf(t, err)
+	if promise.State() != v8.Pending {
+		t.Fatalf("expected promise to be pending, state=%v", promise.State())
+	}
+	go func() {
+		for i := 1; i <= 3; i++ {
+			ch <- i * 10
+		}
+		close(ch)
+	}()
+	fatalIf(t, ctx.Isolate().WaitAsyncCalls(context.Background()))
+	if promise.State() != v8.Fulfilled {
+		t.Fatalf("expected promise to be fulfilled, state=%v", promise.State())
+	}
+	if sum := promise.Result().Int32(); sum != 60 {
+		t.Errorf("unexpected sum %d", sum)
+	}
+
+	fromSeq, err := v8.NewAsyncIterable(ctx, func(yield func(string) bool) { yield("only") })
+	fatalIf(t, err)
+	var items []string
+	fatalIf(t, fromSeq.IterateAsync(context.Background(), func(v *v8.Value) error {
+		items = append(items, v.String())
+		return nil
+	}))
+	if s := strings.Join(items, ","); s != "only" {
+		t.Errorf("unexpected items %q", s)
+	}
+}
+
+func TestNewIterableReleasedByGC(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	seqDone := make(chan struct{})
+	seq := func(yield func(int) bool) {
+		defer close(seqDone)
+		for i := 1; yield(i); i++ {
+		}
+	}
+	ctx.WithTemporaryValues(func() {
+		iterable, err := v8.NewIterable(ctx, seq)
+		fatalIf(t, err)
+		first, err := ctx.RunScript("(it => it[Symbol.iterator]().next().value)", "")
+		fatalIf(t, err)
+		fn, _ := first.AsFunction()
+		val, err := fn.Call(v8.Undefined(iso), iterable)
+		fatalIf(t, err)
+		if val.Int32() != 1 {
+			t.Errorf("unexpected first value %v", val)
+		}
+	})
+	if n := v8.IteratorCount(ctx); n != 1 {
+		t.Fatalf("expected 1 iterator, got %d", n)
+	}
+
+	// The abandoned iterable's sequence is stopped when it's collected:
+	iso.CollectGarbage()
+	select {
+	case <-seqDone:
+	case <-time.After(5 * time.Second):
+		t.Error("sequence function didn't stop after the iterable was collected")
+	}
+	if n := v8.IteratorCount(ctx); n != 0 {
+		t.Errorf("expected the iterator to be released, %d remain", n)
+	}
+}
@@ -35,9 +35,8 @@ func JSONStringify(ctx *Context, val Valuer) (string, error) {
 	if v == nil {
 		return "", errors.New("v8go: Value is required")
 	}
-	// It's OK to use the Isolate's shared buffer because we already require that client code can
-	// only access an Isolate, and Values derived from it, on a single goroutine at a time.
-	buffer := v.ctx.iso.stringBuffer
+	buffer := stringBuffers.Get().(*[kIsolateStringBufferSize]byte)
+	defer stringBuffers.Put(buffer)
 	bufPtr := unsafe.Pointer(&buffer[0])
 
 	s := C.JSONStringify(v.valuePtr(), bufPtr, C.int(len(buffer)))
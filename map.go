@@ -0,0 +1,103 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+
+// Map is a JavaScript `Map`: a collection of key-value pairs in insertion order, whose keys
+// may be of any type.
+//
+// Map's Get, Set, Has and Delete methods operate on its entries, and hide the Object methods
+// of the same names, which operate on its properties.
+type Map struct {
+	*Object
+}
+
+// NewMap creates a new, empty Map.
+func NewMap(ctx *Context) *Map {
+	return &Map{&Object{&Value{C.NewMap(ctx.ptr), ctx}}}
+}
+
+// Get returns the value stored under a key, or `undefined` if there is none.
+// The key may be a *Value or any Go type accepted by Context.NewValue.
+func (m *Map) Get(key interface{}) (*Value, error) {
+	keyVal, err := m.ctx.NewValue(key)
+	if err != nil {
+		return nil, err
+	}
+	return valueResult(m.ctx, C.MapGet(m.valuePtr(), keyVal.valuePtr()))
+}
+
+// Set stores a value under a key, replacing any existing value.
+// The key and value may be *Values or any Go types accepted by Context.NewValue.
+func (m *Map) Set(key interface{}, val interface{}) error {
+	keyVal, err := m.ctx.NewValue(key)
+	if err != nil {
+		return err
+	}
+	valVal, err := m.ctx.NewValue(val)
+	if err != nil {
+		return err
+	}
+	_, err = valueResult(m.ctx, C.MapSet(m.valuePtr(), keyVal.valuePtr(), valVal.valuePtr()))
+	return err
+}
+
+// Has returns true if the Map contains the key.
+func (m *Map) Has(key interface{}) (bool, error) {
+	keyVal, err := m.ctx.NewValue(key)
+	if err != nil {
+		return false, err
+	}
+	return C.MapHas(m.valuePtr(), keyVal.valuePtr()) != 0, nil
+}
+
+// Delete removes a key and its value, returning true if the key was present.
+func (m *Map) Delete(key interface{}) (bool, error) {
+	keyVal, err := m.ctx.NewValue(key)
+	if err != nil {
+		return false, err
+	}
+	return C.MapDelete(m.valuePtr(), keyVal.valuePtr()) != 0, nil
+}
+
+// Clear removes all entries.
+func (m *Map) Clear() {
+	C.MapClear(m.valuePtr())
+}
+
+// Size returns the number of entries.
+func (m *Map) Size() int {
+	return int(C.MapSize(m.valuePtr()))
+}
+
+// AsArray returns a new Array containing the Map's keys and values, alternating:
+// `[key1, value1, key2, value2, ...]`.
+func (m *Map) AsArray() *Array {
+	return &Array{Object{&Value{C.MapAsArray(m.valuePtr()), m.ctx}}}
+}
+
+// ForEach calls the callback with each key and value, in insertion order. Changes made to the
+// Map during the iteration are not seen. If the callback returns an error, iteration stops
+// and ForEach returns that error.
+func (m *Map) ForEach(callback func(key *Value, value *Value) error) error {
+	entries := m.AsArray()
+	n := entries.Length()
+	for i := uint32(0); i+1 < n; i += 2 {
+		key, err := entries.GetIdx(i)
+		if err != nil {
+			return err
+		}
+		val, err := entries.GetIdx(i + 1)
+		if err != nil {
+			return err
+		}
+		if err = callback(key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
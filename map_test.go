@@ -0,0 +1,135 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	m := v8.NewMap(ctx)
+	if !m.IsMap() {
+		t.Fatal("expected IsMap to be true")
+	}
+	fatalIf(t, m.Set("a", 1))
+	fatalIf(t, m.Set(2, "two"))
+	key := ctx.NewObject()
+	fatalIf(t, m.Set(key, true))
+	if n := m.Size(); n != 3 {
+		t.Errorf("unexpected Size %d", n)
+	}
+
+	val, err := m.Get(2)
+	fatalIf(t, err)
+	if val.String() != "two" {
+		t.Errorf("unexpected Get(2): %v", val)
+	}
+	val, err = m.Get(key)
+	fatalIf(t, err)
+	if !val.Boolean() {
+		t.Errorf("unexpected Get(key): %v", val)
+	}
+	val, err = m.Get("missing")
+	fatalIf(t, err)
+	if !val.IsUndefined() {
+		t.Errorf("expected undefined for missing key, got %v", val)
+	}
+	if has, _ := m.Has("a"); !has {
+		t.Error("expected Has(\"a\")")
+	}
+	if has, _ := m.Has("2"); has {
+		t.Error("expected keys to be compared by SameValueZero")
+	}
+
+	var entries []string
+	fatalIf(t, m.ForEach(func(k, v *v8.Value) error {
+		entries = append(entries, fmt.Sprintf("%s=%s", k, v))
+		return nil
+	}))
+	if s := strings.Join(entries, " "); s != "a=1 2=two [object Object]=true" {
+		t.Errorf("unexpected entries: %s", s)
+	}
+	stop := errors.New("stop")
+	if err := m.ForEach(func(k, v *v8.Value) error { return stop }); err != stop {
+		t.Errorf("expected ForEach to return callback's error, got %v", err)
+	}
+	if n := m.AsArray().Length(); n != 6 {
+		t.Errorf("unexpected AsArray length %d", n)
+	}
+
+	if deleted, _ := m.Delete("a"); !deleted {
+		t.Error("expected Delete to return true")
+	}
+	if deleted, _ := m.Delete("a"); deleted {
+		t.Error("expected second Delete to return false")
+	}
+	fatalIf(t, ctx.Global().Set("m", m))
+	val, err = ctx.RunScript("m.size", "")
+	fatalIf(t, err)
+	if val.Int32() != 2 {
+		t.Errorf("unexpected size from JS: %v", val)
+	}
+	m.Clear()
+	if m.Size() != 0 {
+		t.Error("expected Clear to empty the Map")
+	}
+
+	val, err = ctx.RunScript("new Map([[1, 2]])", "")
+	fatalIf(t, err)
+	fromJS, err := val.AsMap()
+	fatalIf(t, err)
+	if fromJS.Size() != 1 {
+		t.Errorf("unexpected Size %d", fromJS.Size())
+	}
+	if _, err := ctx.Global().Value.AsMap(); err == nil {
+		t.Error("expected error converting non-Map")
+	}
+}
+
+func TestNewValueMap(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	val, err := ctx.NewValue(map[int]string{1: "one", 2: "two"})
+	fatalIf(t, err)
+	m, err := val.AsMap()
+	fatalIf(t, err)
+	if m.Size() != 2 {
+		t.Errorf("unexpected Size %d", m.Size())
+	}
+	one, err := m.Get(1)
+	fatalIf(t, err)
+	if one.String() != "one" {
+		t.Errorf("unexpected value %v", one)
+	}
+
+	val, err = ctx.NewValue(map[string]interface{}{"a": 1, "nested": map[float64]bool{0.5: true}})
+	fatalIf(t, err)
+	if val.IsMap() || !val.IsObject() {
+		t.Fatal("expected a string-keyed map to become a plain object")
+	}
+	fatalIf(t, ctx.Global().Set("obj", val))
+	result, err := ctx.RunScript("obj.a + ' ' + obj.nested.get(0.5)", "")
+	fatalIf(t, err)
+	if s := result.String(); s != "1 true" {
+		t.Errorf("unexpected result %s", s)
+	}
+
+	if _, err := ctx.NewValue(map[string]chan int{"c": nil}); err == nil {
+		t.Error("expected error for unsupported map value type")
+	}
+}
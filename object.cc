@@ -74,6 +74,17 @@ int ObjectHasIdx(ValuePtr ptr, uint32_t idx) {
   return _with.obj->Has(_with.local_ctx, idx).ToChecked();
 }
 
+int ObjectHasOwnProperty(ValuePtr ptr, const char* key, int keyLen) {
+  WithObject _with(ptr);
+  Local<String> key_val = _with.makeString(key, NewStringType::kInternalized, keyLen);
+  return _with.obj->HasOwnProperty(_with.local_ctx, key_val).FromMaybe(false);
+}
+
+void ObjectAddGoRef(ValuePtr ptr, GoRefKind kind, int id) {
+  WithObject _with(ptr);
+  _with.ctx->addGoRef(_with.obj, kind, id);
+}
+
 
 int ObjectDelete(ValuePtr ptr, const char* key, int keyLen) {
   WithObject _with(ptr);
@@ -92,6 +103,62 @@ int ObjectDeleteIdx(ValuePtr ptr, uint32_t idx) {
 }
 
 
+// Returns a Maybe<bool> to Go as a RtnValue holding a Boolean, or the pending exception.
+static RtnValue returnBool(WithContext &_with, Maybe<bool> result) {
+  bool b;
+  if (!result.To(&b)) {
+    return _with.returnValue(MaybeLocal<Value>());
+  }
+  return _with.returnValue(MaybeLocal<Value>(Boolean::New(_with.iso(), b)));
+}
+
+ValueRef ObjectGetPrototype(ValuePtr ptr) {
+  WithObject _with(ptr);
+  return _with.returnValue(_with.obj->GetPrototype());
+}
+
+RtnValue ObjectSetPrototype(ValuePtr ptr, ValuePtr proto) {
+  WithObject _with(ptr);
+  return returnBool(_with, _with.obj->SetPrototype(_with.local_ctx, Deref(proto)));
+}
+
+RtnValue ObjectSetIntegrityLevel(ValuePtr ptr, int frozen) {
+  WithObject _with(ptr);
+  IntegrityLevel level = frozen ? IntegrityLevel::kFrozen : IntegrityLevel::kSealed;
+  return returnBool(_with, _with.obj->SetIntegrityLevel(_with.local_ctx, level));
+}
+
+ValueRef ObjectGetConstructorName(ValuePtr ptr) {
+  WithObject _with(ptr);
+  return _with.returnValue(_with.obj->GetConstructorName());
+}
+
+RtnValue ObjectCallIntrinsic(ValuePtr ptr, int which) {
+  WithObject _with(ptr);
+  Local<Function> fn = ptr.ctx->objectIntrinsic(ObjectIntrinsic(which));
+  if (fn.IsEmpty()) {
+    _with.iso()->ThrowException(Exception::TypeError(
+        _with.makeString("Object method is unavailable in this context")));
+    RtnValue rtn = {};
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  Local<Value> arg = _with.obj;
+  return _with.returnValue(fn->Call(_with.local_ctx, Undefined(_with.iso()), 1, &arg));
+}
+
+RtnValue ObjectGetOwnPropertyDescriptor(ValuePtr ptr, ValuePtr key) {
+  WithObject _with(ptr);
+  return _with.returnValue(_with.obj->GetOwnPropertyDescriptor(_with.local_ctx,
+                                                               Deref(key).As<Name>()));
+}
+
+RtnValue ValueInstanceOf(ValuePtr ptr, ValuePtr ctor) {
+  WithValue _with(ptr);
+  return returnBool(_with, _with.value->InstanceOf(_with.local_ctx, Deref(ctor).As<Object>()));
+}
+
+
 /********** Object Internal Fields **********/
 
 int ObjectSetInternalField(ValuePtr ptr, int idx, ValuePtr val_ptr) {
@@ -264,6 +331,83 @@ ValueRef FunctionSourceMapUrl(ValuePtr ptr) {
   return ptr.ctx->addValue(result);
 }
 
+ScriptCompilerCachedData* FunctionCreateCodeCache(ValuePtr ptr) {
+  WithValue _with(ptr);
+  ScriptCompiler::CachedData* cached_data =
+      ScriptCompiler::CreateCodeCacheForFunction(_with.value.As<Function>());
+  if (!cached_data) {
+    return nullptr;
+  }
+  ScriptCompilerCachedData* cd = new ScriptCompilerCachedData;
+  cd->ptr = cached_data;
+  cd->data = cached_data->data;
+  cd->length = cached_data->length;
+  cd->rejected = cached_data->rejected;
+  return cd;
+}
+
+ValueRef FunctionGetName(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Function>()->GetName());
+}
+
+void FunctionSetName(ValuePtr ptr, ValuePtr name) {
+  WithValue _with(ptr);
+  _with.value.As<Function>()->SetName(Deref(name).As<String>());
+}
+
+ValueRef FunctionGetInferredName(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Function>()->GetInferredName());
+}
+
+ValueRef FunctionGetDebugName(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Function>()->GetDebugName());
+}
+
+ScriptOriginInfo FunctionGetScriptOrigin(ValuePtr ptr) {
+  WithValue _with(ptr);
+  ScriptOrigin origin = _with.value.As<Function>()->GetScriptOrigin();
+  ScriptOriginInfo info = {};
+  info.resourceName = _with.returnValue(origin.ResourceName());
+  info.sourceMapUrl = _with.returnValue(origin.SourceMapUrl());
+  info.lineOffset = origin.LineOffset();
+  info.columnOffset = origin.ColumnOffset();
+  info.isShared = origin.Options().IsSharedCrossOrigin();
+  info.isOpaque = origin.Options().IsOpaque();
+  info.isModule = origin.Options().IsModule();
+  return info;
+}
+
+int FunctionScriptId(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Function>()->ScriptId();
+}
+
+int FunctionGetScriptLineNumber(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Function>()->GetScriptLineNumber();
+}
+
+int FunctionGetScriptColumnNumber(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Function>()->GetScriptColumnNumber();
+}
+
+ValueRef FunctionGetBoundFunction(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Function>()->GetBoundFunction());
+}
+
+// A function is native if it's not defined in a script: a builtin, or implemented in Go.
+// Bound functions have no script of their own either, so they're excluded.
+int FunctionIsNative(ValuePtr ptr) {
+  WithValue _with(ptr);
+  Local<Function> fn = _with.value.As<Function>();
+  return fn->ScriptId() == UnboundScript::kNoScriptId && fn->GetBoundFunction()->IsUndefined();
+}
+
 /********** Array **********/
 
 extern ValueRef NewArray(ContextPtr ctx, uint32_t length) {
@@ -279,3 +423,320 @@ uint32_t ArrayLength(ValuePtr ptr) {
     return 0;
   }
 }
+
+/********** Map **********/
+
+ValueRef NewMap(ContextPtr ctx) {
+  WithContext _with(ctx);
+  return _with.returnValue(Map::New(_with.iso()));
+}
+
+RtnValue MapGet(ValuePtr ptr, ValuePtr key) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Map>()->Get(_with.local_ctx, Deref(key)));
+}
+
+RtnValue MapSet(ValuePtr ptr, ValuePtr key, ValuePtr val) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Map>()->Set(_with.local_ctx, Deref(key), Deref(val)));
+}
+
+int MapHas(ValuePtr ptr, ValuePtr key) {
+  WithValue _with(ptr);
+  return _with.value.As<Map>()->Has(_with.local_ctx, Deref(key)).FromMaybe(false);
+}
+
+int MapDelete(ValuePtr ptr, ValuePtr key) {
+  WithValue _with(ptr);
+  return _with.value.As<Map>()->Delete(_with.local_ctx, Deref(key)).FromMaybe(false);
+}
+
+void MapClear(ValuePtr ptr) {
+  WithValue _with(ptr);
+  _with.value.As<Map>()->Clear();
+}
+
+size_t MapSize(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Map>()->Size();
+}
+
+ValueRef MapAsArray(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Map>()->AsArray());
+}
+
+/********** Set **********/
+
+ValueRef NewSet(ContextPtr ctx) {
+  WithContext _with(ctx);
+  return _with.returnValue(Set::New(_with.iso()));
+}
+
+RtnValue SetAdd(ValuePtr ptr, ValuePtr key) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Set>()->Add(_with.local_ctx, Deref(key)));
+}
+
+int SetHas(ValuePtr ptr, ValuePtr key) {
+  WithValue _with(ptr);
+  return _with.value.As<Set>()->Has(_with.local_ctx, Deref(key)).FromMaybe(false);
+}
+
+int SetDelete(ValuePtr ptr, ValuePtr key) {
+  WithValue _with(ptr);
+  return _with.value.As<Set>()->Delete(_with.local_ctx, Deref(key)).FromMaybe(false);
+}
+
+void SetClear(ValuePtr ptr) {
+  WithValue _with(ptr);
+  _with.value.As<Set>()->Clear();
+}
+
+size_t SetSize(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Set>()->Size();
+}
+
+ValueRef SetAsArray(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Set>()->AsArray());
+}
+
+/********** Date **********/
+
+RtnValue NewDate(ContextPtr ctx, double time) {
+  WithContext _with(ctx);
+  return _with.returnValue(Date::New(_with.local_ctx, time));
+}
+
+double DateValueOf(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Date>()->ValueOf();
+}
+
+/********** Error **********/
+
+RtnValue NewError(ContextPtr ctx, int kind, ValuePtr message, ValuePtr cause) {
+  WithContext _with(ctx);
+  Local<String> msg = Deref(message).As<String>();
+  Local<Value> error;
+  switch (kind) {
+    case TypeError_kind:      error = Exception::TypeError(msg); break;
+    case RangeError_kind:     error = Exception::RangeError(msg); break;
+    case SyntaxError_kind:    error = Exception::SyntaxError(msg); break;
+    case ReferenceError_kind: error = Exception::ReferenceError(msg); break;
+    default:                  error = Exception::Error(msg); break;
+  }
+  if (cause.ctx) {
+    // Like `new Error(message, {cause})`, which makes `cause` a non-enumerable own property.
+    Local<String> key = String::NewFromUtf8Literal(_with.iso(), "cause");
+    if (error.As<Object>()->DefineOwnProperty(_with.local_ctx, key, Deref(cause),
+                                              DontEnum).IsNothing()) {
+      return _with.returnValue(MaybeLocal<Value>());
+    }
+  }
+  return _with.returnValue(MaybeLocal<Value>(error));
+}
+
+/********** Proxy **********/
+
+RtnValue NewProxy(ValuePtr target, ValuePtr handler) {
+  WithValue _with(target);
+  return _with.returnValue(Proxy::New(_with.local_ctx, _with.value.As<Object>(),
+                                      Deref(handler).As<Object>()));
+}
+
+ValueRef ProxyGetTarget(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Proxy>()->GetTarget());
+}
+
+ValueRef ProxyGetHandler(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Proxy>()->GetHandler());
+}
+
+int ProxyIsRevoked(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<Proxy>()->IsRevoked();
+}
+
+void ProxyRevoke(ValuePtr ptr) {
+  WithValue _with(ptr);
+  _with.value.As<Proxy>()->Revoke();
+}
+
+/********** ArrayBuffer **********/
+
+static void freeBackingStore(void* data, size_t length, void* deleterData) {
+  auto allocator = static_cast<std::shared_ptr<V8GoAllocator>*>(deleterData);
+  (*allocator)->Free(data, length);
+  delete allocator;
+}
+
+// Allocates the memory for a new backing store from the isolate's allocator, returning nullptr
+// if the allocator refuses. (ArrayBuffer::New and NewBackingStore crash instead.) The allocator
+// is the only place the isolate's limit is enforced, so this can't race with other threads.
+static void* allocateBackingStore(Isolate* iso, size_t length, bool zeroed, void** deleterData) {
+  V8GoAllocator* allocator = V8GoAllocator::forIsolate(iso);
+  void* data = zeroed ? allocator->Allocate(length) : allocator->AllocateUninitialized(length);
+  if (data) {
+    *deleterData = new std::shared_ptr<V8GoAllocator>(allocator->shared_from_this());
+  }
+  return data;
+}
+
+static RtnValue allocationFailed(WithContext& _with) {
+  _with.iso()->ThrowException(Exception::RangeError(
+      _with.makeString("Array buffer allocation failed")));
+  RtnValue rtn = {};
+  rtn.error = _with.exceptionError();
+  return rtn;
+}
+
+RtnValue NewArrayBuffer(ContextPtr ctx, const void* data, size_t length) {
+  WithContext _with(ctx);
+  if (length == 0) {
+    return _with.returnValue(MaybeLocal<ArrayBuffer>(ArrayBuffer::New(_with.iso(), 0)));
+  }
+  void* deleterData;
+  void* bytes = allocateBackingStore(_with.iso(), length, false, &deleterData);
+  if (!bytes) {
+    return allocationFailed(_with);
+  }
+  memcpy(bytes, data, length);
+  Local<ArrayBuffer> buffer = ArrayBuffer::New(_with.iso(), ArrayBuffer::NewBackingStore(
+      bytes, length, freeBackingStore, deleterData));
+  return _with.returnValue(MaybeLocal<ArrayBuffer>(buffer));
+}
+
+// Works with both ArrayBuffers and SharedArrayBuffers.
+static std::shared_ptr<BackingStore> getBackingStore(Local<Value> buffer) {
+  if (buffer->IsSharedArrayBuffer()) {
+    return buffer.As<SharedArrayBuffer>()->GetBackingStore();
+  } else {
+    return buffer.As<ArrayBuffer>()->GetBackingStore();
+  }
+}
+
+ArrayBufferContents ArrayBufferGetContents(ValuePtr ptr) {
+  WithValue _with(ptr);
+  std::shared_ptr<BackingStore> store = getBackingStore(_with.value);
+  // The ArrayBuffer itself keeps the backing store alive, so it's safe to return its address
+  // as long as the caller holds onto the Value.
+  return {store->Data(), store->ByteLength()};
+}
+
+int ArrayBufferIsDetachable(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<ArrayBuffer>()->IsDetachable();
+}
+
+int ArrayBufferDetach(ValuePtr ptr) {
+  WithValue _with(ptr);
+  Local<ArrayBuffer> buffer = _with.value.As<ArrayBuffer>();
+  if (!buffer->IsDetachable()) {
+    return false;
+  }
+  buffer->Detach();
+  return true;
+}
+
+RtnValue ArrayBufferTransfer(ValuePtr ptr, ContextPtr ctx) {
+  std::shared_ptr<BackingStore> store;
+  {
+    // Release the source isolate before locking the destination one.
+    WithValue _with(ptr);
+    Local<ArrayBuffer> buffer = _with.value.As<ArrayBuffer>();
+    store = buffer->GetBackingStore();
+    buffer->Detach();
+  }
+  WithContext _with(ctx);
+  Local<ArrayBuffer> buffer = ArrayBuffer::New(_with.iso(), std::move(store));
+  return _with.returnValue(MaybeLocal<ArrayBuffer>(buffer));
+}
+
+/********** SharedArrayBuffer **********/
+
+RtnValue NewSharedArrayBuffer(ContextPtr ctx, size_t length) {
+  WithContext _with(ctx);
+  if (length == 0) {
+    return _with.returnValue(MaybeLocal<SharedArrayBuffer>(SharedArrayBuffer::New(_with.iso(), 0)));
+  }
+  void* deleterData;
+  void* bytes = allocateBackingStore(_with.iso(), length, true, &deleterData);
+  if (!bytes) {
+    return allocationFailed(_with);
+  }
+  Local<SharedArrayBuffer> buffer = SharedArrayBuffer::New(_with.iso(),
+      SharedArrayBuffer::NewBackingStore(bytes, length, freeBackingStore, deleterData));
+  return _with.returnValue(MaybeLocal<SharedArrayBuffer>(buffer));
+}
+
+RtnValue SharedArrayBufferShareWith(ValuePtr ptr, ContextPtr ctx) {
+  std::shared_ptr<BackingStore> store;
+  {
+    // Release the source isolate before locking the destination one.
+    WithValue _with(ptr);
+    store = _with.value.As<SharedArrayBuffer>()->GetBackingStore();
+  }
+  WithContext _with(ctx);
+  Local<SharedArrayBuffer> buffer = SharedArrayBuffer::New(_with.iso(), std::move(store));
+  return _with.returnValue(MaybeLocal<SharedArrayBuffer>(buffer));
+}
+
+/********** TypedArray **********/
+
+template <class T>
+static Local<TypedArray> newTypedArray(Local<Value> buffer, size_t byteOffset, size_t length) {
+  if (buffer->IsSharedArrayBuffer()) {
+    return T::New(buffer.As<SharedArrayBuffer>(), byteOffset, length);
+  } else {
+    return T::New(buffer.As<ArrayBuffer>(), byteOffset, length);
+  }
+}
+
+RtnValue NewTypedArray(ValuePtr ptr, int kind, size_t byteOffset, size_t length) {
+  WithValue _with(ptr);
+  Local<TypedArray> array;
+  switch (kind) {
+    case Uint8Array_kind:         array = newTypedArray<Uint8Array>(_with.value, byteOffset, length); break;
+    case Uint8ClampedArray_kind:  array = newTypedArray<Uint8ClampedArray>(_with.value, byteOffset, length); break;
+    case Int8Array_kind:          array = newTypedArray<Int8Array>(_with.value, byteOffset, length); break;
+    case Uint16Array_kind:        array = newTypedArray<Uint16Array>(_with.value, byteOffset, length); break;
+    case Int16Array_kind:         array = newTypedArray<Int16Array>(_with.value, byteOffset, length); break;
+    case Uint32Array_kind:        array = newTypedArray<Uint32Array>(_with.value, byteOffset, length); break;
+    case Int32Array_kind:         array = newTypedArray<Int32Array>(_with.value, byteOffset, length); break;
+    case Float32Array_kind:       array = newTypedArray<Float32Array>(_with.value, byteOffset, length); break;
+    case Float64Array_kind:       array = newTypedArray<Float64Array>(_with.value, byteOffset, length); break;
+    case BigInt64Array_kind:      array = newTypedArray<BigInt64Array>(_with.value, byteOffset, length); break;
+    case BigUint64Array_kind:     array = newTypedArray<BigUint64Array>(_with.value, byteOffset, length); break;
+    default: {
+      RtnValue rtn = {};
+      rtn.error.msg = strdup("invalid TypedArray kind");
+      return rtn;
+    }
+  }
+  return _with.returnValue(MaybeLocal<TypedArray>(array));
+}
+
+ValueRef ArrayBufferViewBuffer(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<ArrayBufferView>()->Buffer());
+}
+
+size_t ArrayBufferViewByteOffset(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<ArrayBufferView>()->ByteOffset();
+}
+
+size_t ArrayBufferViewByteLength(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<ArrayBufferView>()->ByteLength();
+}
+
+size_t TypedArrayLength(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.value.As<TypedArray>()->Length();
+}
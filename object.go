@@ -11,6 +11,8 @@ static RtnValue ObjectGetGo(ValuePtr ptr, _GoString_ key) {
 	return ObjectGet(ptr, _GoStringPtr(key), _GoStringLen(key)); }
 static int ObjectHasGo(ValuePtr ptr, _GoString_ key) {
 	return ObjectHas(ptr, _GoStringPtr(key), _GoStringLen(key)); }
+static int ObjectHasOwnPropertyGo(ValuePtr ptr, _GoString_ key) {
+	return ObjectHasOwnProperty(ptr, _GoStringPtr(key), _GoStringLen(key)); }
 static void ObjectSetGo(ValuePtr ptr, _GoString_ key, ValuePtr val_ptr) {
 	ObjectSet(ptr, _GoStringPtr(key), _GoStringLen(key), val_ptr); }
 static int ObjectDeleteGo(ValuePtr ptr, _GoString_ key) {
@@ -18,6 +20,7 @@ static int ObjectDeleteGo(ValuePtr ptr, _GoString_ key) {
 */
 import "C"
 import (
+	"errors"
 	"fmt"
 )
 
@@ -62,7 +65,7 @@ func (o *Object) Set(key string, val interface{}) error {
 	return nil
 }
 
-// SetKey is like Set except that the key is passed as a Value (which must be a string.)
+// SetKey is like Set except that the key is passed as a Value (a string or Symbol.)
 // This is slightly faster since V8 does not have to create a new String object.
 func (o *Object) SetKey(key *Value, val interface{}) error {
 	value, err := o.ctx.NewValue(val)
@@ -118,7 +121,7 @@ func (o *Object) Get(key string) (*Value, error) {
 	return valueResult(o.ctx, rtn)
 }
 
-// GetKey is like Get except that the key is passed as a Value (which must be a string.)
+// GetKey is like Get except that the key is passed as a Value (a string or Symbol.)
 // This is slightly faster since V8 does not have to create a new String object.
 func (o *Object) GetKey(key *Value) (*Value, error) {
 	rtn := C.ObjectGetKey(o.valuePtr(), key.valuePtr())
@@ -148,12 +151,18 @@ func (o *Object) Has(key string) bool {
 	return C.ObjectHasGo(o.valuePtr(), key) != 0
 }
 
-// HasKey is like Has except that the key is passed as a Value (which must be a string.)
+// HasKey is like Has except that the key is passed as a Value (a string or Symbol.)
 // This is slightly faster since V8 does not have to create a new String object.
 func (o *Object) HasKey(key *Value) bool {
 	return C.ObjectHasKey(o.valuePtr(), key.valuePtr()) != 0
 }
 
+// HasOwnProperty returns true if the object itself has the property, not counting its
+// prototype chain, like `Object.prototype.hasOwnProperty`.
+func (o *Object) HasOwnProperty(key string) bool {
+	return C.ObjectHasOwnPropertyGo(o.valuePtr(), key) != 0
+}
+
 // HasIdx returns true if the object has a value at the given index.
 func (o *Object) HasIdx(idx uint32) bool {
 	return C.ObjectHasIdx(o.valuePtr(), C.uint32_t(idx)) != 0
@@ -164,7 +173,7 @@ func (o *Object) Delete(key string) bool {
 	return C.ObjectDeleteGo(o.valuePtr(), key) != 0
 }
 
-// DeleteKey is like Delete except that the key is passed as a Value (which must be a string.)
+// DeleteKey is like Delete except that the key is passed as a Value (a string or Symbol.)
 // This is slightly faster since V8 does not have to create a new String object.
 func (o *Object) DeleteKey(key *Value) bool {
 	return C.ObjectDeleteKey(o.valuePtr(), key.valuePtr()) != 0
@@ -174,3 +183,182 @@ func (o *Object) DeleteKey(key *Value) bool {
 func (o *Object) DeleteIdx(idx uint32) bool {
 	return C.ObjectDeleteIdx(o.valuePtr(), C.uint32_t(idx)) != 0
 }
+
+// GetPrototype returns the object's prototype, which is an Object or `null`.
+func (o *Object) GetPrototype() *Value {
+	return &Value{C.ObjectGetPrototype(o.valuePtr()), o.ctx}
+}
+
+// SetPrototype sets the object's prototype, like `Object.setPrototypeOf`. The prototype must
+// be an Object or `null`. Fails if the object is not extensible, or if the change would
+// create a cycle.
+func (o *Object) SetPrototype(proto Valuer) error {
+	protoVal := proto.value()
+	if !protoVal.IsObject() && !protoVal.IsNull() {
+		return errors.New("v8go: prototype must be an Object or null")
+	}
+	ok, err := boolResult(o.ctx, C.ObjectSetPrototype(o.valuePtr(), protoVal.valuePtr()))
+	if err == nil && !ok {
+		err = errors.New("v8go: could not set prototype")
+	}
+	return err
+}
+
+// IntegrityLevel is a level of protection against changes to an object's properties.
+type IntegrityLevel int
+
+const (
+	// Sealed objects can't have properties added or deleted, or reconfigured.
+	IntegrityLevelSealed IntegrityLevel = iota
+	// Frozen objects are sealed, and also can't have their properties' values changed.
+	IntegrityLevelFrozen
+)
+
+// SetIntegrityLevel seals or freezes the object, like `Object.seal` or `Object.freeze`.
+// This only affects the object's own properties; the objects they refer to are unaffected.
+func (o *Object) SetIntegrityLevel(level IntegrityLevel) error {
+	frozen := 0
+	if level == IntegrityLevelFrozen {
+		frozen = 1
+	}
+	ok, err := boolResult(o.ctx, C.ObjectSetIntegrityLevel(o.valuePtr(), C.int(frozen)))
+	if err == nil && !ok {
+		err = errors.New("v8go: could not set integrity level")
+	}
+	return err
+}
+
+// Freeze is the same as SetIntegrityLevel(IntegrityLevelFrozen).
+func (o *Object) Freeze() error {
+	return o.SetIntegrityLevel(IntegrityLevelFrozen)
+}
+
+// Seal is the same as SetIntegrityLevel(IntegrityLevelSealed).
+func (o *Object) Seal() error {
+	return o.SetIntegrityLevel(IntegrityLevelSealed)
+}
+
+// PreventExtensions prevents new properties from being added to the object, like
+// `Object.preventExtensions`.
+func (o *Object) PreventExtensions() error {
+	_, err := o.callIntrinsic(preventExtensionsFn)
+	return err
+}
+
+// IsExtensible returns true if new properties can be added to the object.
+func (o *Object) IsExtensible() bool {
+	result, err := o.callIntrinsic(isExtensibleFn)
+	return err == nil && result.Boolean()
+}
+
+// IsSealed returns true if the object is sealed (see SetIntegrityLevel).
+func (o *Object) IsSealed() bool {
+	result, err := o.callIntrinsic(isSealedFn)
+	return err == nil && result.Boolean()
+}
+
+// IsFrozen returns true if the object is frozen (see SetIntegrityLevel).
+func (o *Object) IsFrozen() bool {
+	result, err := o.callIntrinsic(isFrozenFn)
+	return err == nil && result.Boolean()
+}
+
+type objectIntrinsic int
+
+// This MUST be kept in sync with `ObjectIntrinsic` in v8go.h!
+const (
+	preventExtensionsFn objectIntrinsic = iota
+	isExtensibleFn
+	isSealedFn
+	isFrozenFn
+)
+
+// V8 has no C++ API for some operations, so this calls a static method of `Object`. The
+// method was saved when the Context was created, so scripts can't interfere by replacing it.
+// Returns an error if the Context's global template replaced `Object` and the method was missing.
+func (o *Object) callIntrinsic(which objectIntrinsic) (*Value, error) {
+	return valueResult(o.ctx, C.ObjectCallIntrinsic(o.valuePtr(), C.int(which)))
+}
+
+// GetConstructorName returns the name of the object's constructor, e.g. "Object" or "Map".
+func (o *Object) GetConstructorName() string {
+	return (&Value{C.ObjectGetConstructorName(o.valuePtr()), o.ctx}).String()
+}
+
+// PropertyDescriptor describes an object's own property, as returned by
+// `Object.getOwnPropertyDescriptor`. A data property has a Value; an accessor property has
+// a Get and/or Set function instead.
+type PropertyDescriptor struct {
+	Value        *Value // The value of a data property; nil for an accessor property
+	Get          *Value // The getter of an accessor property, or nil
+	Set          *Value // The setter of an accessor property, or nil
+	Writable     bool   // True if a data property's value can be changed
+	Enumerable   bool   // True if the property shows up in `for...in` and `Object.keys`
+	Configurable bool   // True if the property can be deleted or redefined
+}
+
+// GetOwnPropertyDescriptor returns the descriptor of the object's own property with the given
+// name, or nil if there is no such property (even if it's inherited from a prototype).
+func (o *Object) GetOwnPropertyDescriptor(key string) (*PropertyDescriptor, error) {
+	keyVal, err := o.ctx.NewValue(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.GetOwnPropertyDescriptorKey(keyVal)
+}
+
+// GetOwnPropertyDescriptorKey is like GetOwnPropertyDescriptor except that the key is passed
+// as a Value (a string or Symbol.)
+func (o *Object) GetOwnPropertyDescriptorKey(key *Value) (*PropertyDescriptor, error) {
+	if !key.IsName() {
+		return nil, errors.New("v8go: property key must be a string or Symbol")
+	}
+	rtn := C.ObjectGetOwnPropertyDescriptor(o.valuePtr(), key.valuePtr())
+	descVal, err := valueResult(o.ctx, rtn)
+	if err != nil || descVal.IsUndefined() {
+		return nil, err
+	}
+	desc, err := descVal.AsObject()
+	if err != nil {
+		return nil, err
+	}
+	var result PropertyDescriptor
+	for _, field := range []struct {
+		name string
+		dst  **Value
+	}{{"value", &result.Value}, {"get", &result.Get}, {"set", &result.Set}} {
+		if desc.HasOwnProperty(field.name) {
+			if *field.dst, err = desc.Get(field.name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, field := range []struct {
+		name string
+		dst  *bool
+	}{{"writable", &result.Writable}, {"enumerable", &result.Enumerable}, {"configurable", &result.Configurable}} {
+		if desc.HasOwnProperty(field.name) {
+			val, err := desc.Get(field.name)
+			if err != nil {
+				return nil, err
+			}
+			*field.dst = val.Boolean()
+		}
+	}
+	if result.Get != nil && result.Get.IsUndefined() {
+		result.Get = nil
+	}
+	if result.Set != nil && result.Set.IsUndefined() {
+		result.Set = nil
+	}
+	return &result, nil
+}
+
+// Converts a RtnValue holding a Boolean, as returned by some C functions.
+func boolResult(ctx *Context, rtn C.RtnValue) (bool, error) {
+	val, err := valueResult(ctx, rtn)
+	if err != nil {
+		return false, err
+	}
+	return val.Boolean(), nil
+}
@@ -268,6 +268,173 @@ func TestObjectDelete(t *testing.T) {
 
 }
 
+func TestObjectPrototypeAndIntegrity(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	val, _ := ctx.RunScript("class Foo {}; var proto = {greet() { return 'hi' }}; new Foo()", "")
+	obj, _ := val.AsObject()
+	if name := obj.GetConstructorName(); name != "Foo" {
+		t.Errorf("expected constructor name Foo, got %q", name)
+	}
+	fooCtor, _ := ctx.Global().Get("Foo")
+	if ok, err := val.InstanceOf(fooCtor); err != nil || !ok {
+		t.Errorf("expected instanceof Foo, got %v, %v", ok, err)
+	}
+	if _, err := val.InstanceOf(obj); err == nil {
+		t.Error("expected error using a non-constructor with InstanceOf")
+	}
+
+	proto, _ := ctx.Global().Get("proto")
+	if err := obj.SetPrototype(proto); err != nil {
+		t.Fatalf("unexpected error setting prototype: %v", err)
+	}
+	if !obj.GetPrototype().SameValue(proto) {
+		t.Error("expected prototype to be changed")
+	}
+	if result, _ := obj.MethodCall("greet"); result.String() != "hi" {
+		t.Errorf("expected inherited method to return hi, got %q", result)
+	}
+	if err := obj.SetPrototype(v8.Undefined(iso)); err == nil {
+		t.Error("expected error setting prototype to undefined")
+	}
+
+	if !obj.IsExtensible() || obj.IsSealed() || obj.IsFrozen() {
+		t.Error("expected new object to be extensible and not sealed or frozen")
+	}
+	obj.Set("x", 1)
+	if err := obj.Seal(); err != nil {
+		t.Fatalf("unexpected error sealing: %v", err)
+	}
+	if obj.IsExtensible() || !obj.IsSealed() || obj.IsFrozen() {
+		t.Error("expected object to be sealed but not frozen")
+	}
+	if err := obj.Freeze(); err != nil {
+		t.Fatalf("unexpected error freezing: %v", err)
+	}
+	if !obj.IsFrozen() {
+		t.Error("expected object to be frozen")
+	}
+	ctx.Global().Set("frozen", obj)
+	if _, err := ctx.RunScript("'use strict'; frozen.x = 2", ""); err == nil {
+		t.Error("expected error assigning to a frozen property")
+	}
+	if err := obj.SetPrototype(v8.Null(iso)); err == nil {
+		t.Error("expected error changing prototype of a non-extensible object")
+	}
+
+	val, _ = ctx.RunScript("({a: 1})", "")
+	other, _ := val.AsObject()
+	if err := other.PreventExtensions(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other.IsExtensible() || other.IsSealed() {
+		t.Error("expected object to be non-extensible but not sealed")
+	}
+}
+
+func TestObjectGetOwnPropertyDescriptor(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	val, _ := ctx.RunScript(`
+		const o = Object.create({inherited: 1}, {
+			ro: {value: 42, enumerable: true},
+			[Symbol.for('s')]: {value: 'sym', writable: true, configurable: true},
+		});
+		Object.defineProperty(o, 'acc', {get() { return 7 }});
+		o`, "")
+	obj, _ := val.AsObject()
+
+	desc, err := obj.GetOwnPropertyDescriptor("ro")
+	if err != nil || desc == nil {
+		t.Fatalf("expected descriptor, got %v, %v", desc, err)
+	}
+	if desc.Value.Int32() != 42 || desc.Writable || !desc.Enumerable || desc.Configurable {
+		t.Errorf("unexpected data descriptor %+v", desc)
+	}
+	if desc.Get != nil || desc.Set != nil {
+		t.Errorf("expected no accessors on a data property, got %+v", desc)
+	}
+
+	desc, err = obj.GetOwnPropertyDescriptor("acc")
+	if err != nil || desc == nil {
+		t.Fatalf("expected descriptor, got %v, %v", desc, err)
+	}
+	if desc.Value != nil || desc.Get == nil || !desc.Get.IsFunction() || desc.Set != nil {
+		t.Errorf("unexpected accessor descriptor %+v", desc)
+	}
+
+	sym, _ := v8.SymbolFor(ctx, "s")
+	desc, err = obj.GetOwnPropertyDescriptorKey(sym.Value)
+	if err != nil || desc == nil {
+		t.Fatalf("expected descriptor, got %v, %v", desc, err)
+	}
+	if desc.Value.String() != "sym" || !desc.Writable || desc.Enumerable || !desc.Configurable {
+		t.Errorf("unexpected symbol descriptor %+v", desc)
+	}
+
+	if desc, err = obj.GetOwnPropertyDescriptor("inherited"); err != nil || desc != nil {
+		t.Errorf("expected no descriptor for inherited property, got %v, %v", desc, err)
+	}
+	num, _ := v8.NewValue(iso, int32(1))
+	if _, err = obj.GetOwnPropertyDescriptorKey(num); err == nil {
+		t.Error("expected error for a non-name key")
+	}
+}
+
+func TestObjectIntrospectionResistsTampering(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	val, err := ctx.RunScript(`
+		const o = Object.defineProperty({}, 'acc', {get() { return 1 }});
+		Object.prototype.value = 'polluted';
+		Object.prototype.set = function() {};
+		Object.prototype.writable = true;
+		Object.isFrozen = () => true;
+		globalThis.Object = {isExtensible: () => false, isSealed: () => true};
+		o`, "")
+	fatalIf(t, err)
+	obj, _ := val.AsObject()
+	if !obj.IsExtensible() || obj.IsSealed() || obj.IsFrozen() {
+		t.Error("expected the original Object methods to be used")
+	}
+
+	desc, err := obj.GetOwnPropertyDescriptor("acc")
+	fatalIf(t, err)
+	if desc.Value != nil || desc.Set != nil || desc.Writable {
+		t.Errorf("expected inherited properties to be ignored, got %+v", desc)
+	}
+}
+
+func TestObjectIntrospectionWithoutObject(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	global := v8.NewObjectTemplate(iso)
+	fatalIf(t, global.Set("Object", int32(42)))
+	ctx := v8.NewContext(iso, global)
+	defer ctx.Close()
+
+	obj := ctx.NewObject()
+	if err := obj.PreventExtensions(); err == nil {
+		t.Error("expected error when the context has no Object constructor")
+	}
+	if obj.IsFrozen() {
+		t.Error("expected IsFrozen to be false when it can't be checked")
+	}
+}
+
 func ExampleObject_global() {
 	iso := v8.NewIsolate()
 	defer iso.Dispose()
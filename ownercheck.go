@@ -0,0 +1,15 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+//go:build ownercheck
+// +build ownercheck
+
+package v8go
+
+// In builds with the `ownercheck` tag, using an Isolate, or a Context or Value belonging to
+// it, on a goroutine other than the one holding the Isolate's lock (see Isolate.Lock) panics
+// with a clear message, instead of causing undefined behavior in V8. An Isolate that isn't
+// locked belongs to the goroutine that last used it, so handing it to another goroutine
+// requires calling Lock and Unlock.
+const checkOwnership = true
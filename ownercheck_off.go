@@ -0,0 +1,11 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+//go:build !ownercheck
+// +build !ownercheck
+
+package v8go
+
+// See ownercheck.go.
+const checkOwnership = false
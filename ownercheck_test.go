@@ -0,0 +1,101 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+//go:build ownercheck
+// +build ownercheck
+
+package v8go_test
+
+import (
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestOwnerCheck(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	val, err := ctx.RunScript("'hello'", "owner.js")
+	fatalIf(t, err)
+
+	iso.Lock()
+	defer iso.Unlock()
+	if val.String() != "hello" {
+		t.Errorf("unexpected value %q", val.String())
+	}
+
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		_ = val.String()
+	}()
+	if msg, _ := (<-panicked).(string); !strings.Contains(msg, "locked by goroutine") {
+		t.Errorf("expected a panic about the goroutine holding the lock, got %q", msg)
+	}
+}
+
+func TestOwnerCheckExecutor(t *testing.T) {
+	t.Parallel()
+
+	e := v8.NewExecutor(v8.IsolateOptions{})
+	defer e.Close()
+	var ctx *v8.Context
+	fatalIf(t, e.Run(func(iso *v8.Isolate) error {
+		ctx = v8.NewContext(iso)
+		return nil
+	}))
+	defer e.Run(func(iso *v8.Isolate) error {
+		ctx.Close()
+		return nil
+	})
+
+	// Using the Executor's Isolate outside Run panics:
+	defer func() {
+		if msg, _ := recover().(string); !strings.Contains(msg, "locked by goroutine") {
+			t.Errorf("expected a panic about the goroutine holding the lock, got %q", msg)
+		}
+	}()
+	ctx.Global()
+}
+
+func TestOwnerCheckLastUser(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+	val, err := ctx.RunScript("'hello'", "owner.js")
+	fatalIf(t, err)
+
+	// Without Lock, the Isolate belongs to the goroutine that used it last:
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		_ = val.String()
+	}()
+	if msg, _ := (<-panicked).(string); !strings.Contains(msg, "last used by goroutine") {
+		t.Errorf("expected a panic about the goroutine that last used it, got %q", msg)
+	}
+
+	// Lock and Unlock hand it over to another goroutine, and back:
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		iso.Lock()
+		defer iso.Unlock()
+		if val.String() != "hello" {
+			t.Errorf("unexpected value %q", val.String())
+		}
+	}()
+	<-done
+	if val.String() != "hello" {
+		t.Errorf("unexpected value %q", val.String())
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Proxy is a JavaScript `Proxy`: an object that intercepts operations on a target object by
+// calling the "trap" methods of a handler.
+type Proxy struct {
+	*Object
+}
+
+// NewProxy creates a Proxy, like `new Proxy(target, handler)`. The handler is a JavaScript
+// object whose methods, such as `get` and `set`, are the traps.
+func NewProxy(ctx *Context, target *Object, handler *Object) (*Proxy, error) {
+	if target == nil || handler == nil {
+		return nil, errors.New("v8go: Proxy target and handler are required")
+	}
+	obj, err := objectResult(ctx, C.NewProxy(target.valuePtr(), handler.valuePtr()))
+	if err != nil {
+		return nil, err
+	}
+	return &Proxy{obj}, nil
+}
+
+// Target returns the Proxy's target object, or `null` if the Proxy has been revoked.
+func (p *Proxy) Target() *Value {
+	return &Value{C.ProxyGetTarget(p.valuePtr()), p.ctx}
+}
+
+// Handler returns the Proxy's handler object, or `null` if the Proxy has been revoked.
+// For a Proxy created by NewProxyFunc this is the JavaScript object that calls the Go handler.
+func (p *Proxy) Handler() *Value {
+	return &Value{C.ProxyGetHandler(p.valuePtr()), p.ctx}
+}
+
+// IsRevoked returns true if the Proxy has been revoked.
+func (p *Proxy) IsRevoked() bool {
+	return C.ProxyIsRevoked(p.valuePtr()) != 0
+}
+
+// Revoke disconnects the Proxy from its target and handler; afterwards any operation on the
+// Proxy throws a TypeError. This is how a membrane cuts off access to the objects behind it.
+// A Go handler passed to NewProxyFunc is released.
+func (p *Proxy) Revoke() {
+	if id, _ := goProxyHandlerOf(p.Handler()); id != 0 {
+		delete(p.ctx.proxyHandlers, id)
+	}
+	C.ProxyRevoke(p.valuePtr())
+}
+
+// ProxyHandler is the Go handler of a Proxy created by NewProxyFunc. It may be any value;
+// the traps it handles are the ones whose interfaces it implements: ProxyGetTrap,
+// ProxySetTrap, ProxyHasTrap, ProxyDeletePropertyTrap, ProxyOwnKeysTrap, ProxyApplyTrap and
+// ProxyConstructTrap. Operations without a trap are forwarded to the target as usual.
+//
+// A trap that returns an error throws it as a JavaScript exception; see NewError.
+type ProxyHandler interface{}
+
+// ProxyGetTrap handles getting a property value.
+type ProxyGetTrap interface {
+	Get(target *Object, key *Value, receiver *Value) (*Value, error)
+}
+
+// ProxySetTrap handles setting a property value. Returning false makes the assignment throw a
+// TypeError in strict-mode code.
+type ProxySetTrap interface {
+	Set(target *Object, key *Value, value *Value, receiver *Value) (bool, error)
+}
+
+// ProxyHasTrap handles the `in` operator.
+type ProxyHasTrap interface {
+	Has(target *Object, key *Value) (bool, error)
+}
+
+// ProxyDeletePropertyTrap handles the `delete` operator.
+type ProxyDeletePropertyTrap interface {
+	DeleteProperty(target *Object, key *Value) (bool, error)
+}
+
+// ProxyOwnKeysTrap handles `Object.keys`, `Reflect.ownKeys`, etc. Each key must be a string
+// or Symbol.
+type ProxyOwnKeysTrap interface {
+	OwnKeys(target *Object) ([]*Value, error)
+}
+
+// ProxyApplyTrap handles calling the Proxy as a function. It's only called if the target is
+// a function.
+type ProxyApplyTrap interface {
+	Apply(target *Function, this *Value, args []*Value) (*Value, error)
+}
+
+// ProxyConstructTrap handles the `new` operator. It's only called if the target is a
+// constructor.
+type ProxyConstructTrap interface {
+	Construct(target *Function, args []*Value, newTarget *Value) (*Object, error)
+}
+
+// NewProxyFunc creates a Proxy whose traps are implemented by a Go handler.
+func NewProxyFunc(ctx *Context, target *Object, handler ProxyHandler) (*Proxy, error) {
+	if target == nil || handler == nil {
+		return nil, errors.New("v8go: Proxy target and handler are required")
+	}
+	iso := ctx.iso
+	handlerObj, err := iso.proxyHandlerTemplate().NewInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id := atomic.AddInt32(&goProxyHandlerSeq, 1)
+	if err = handlerObj.SetInternalField(0, id); err != nil {
+		return nil, err
+	}
+	for name, implemented := range proxyTraps(handler) {
+		if implemented {
+			if err = handlerObj.Set(name, iso.proxyTrapTmpls[name].GetFunction(ctx)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	proxy, err := NewProxy(ctx, target, handlerObj)
+	if err != nil {
+		return nil, err
+	}
+	// The Go handler is released when the handler object is garbage-collected:
+	if ctx.proxyHandlers == nil {
+		ctx.proxyHandlers = map[int32]ProxyHandler{}
+	}
+	ctx.proxyHandlers[id] = handler
+	ctx.addGoRef(handlerObj, proxyHandlerRef, id)
+	return proxy, nil
+}
+
+// Returns the names of the traps, and whether the handler implements each.
+func proxyTraps(handler ProxyHandler) map[string]bool {
+	_, get := handler.(ProxyGetTrap)
+	_, set := handler.(ProxySetTrap)
+	_, has := handler.(ProxyHasTrap)
+	_, deleteProperty := handler.(ProxyDeletePropertyTrap)
+	_, ownKeys := handler.(ProxyOwnKeysTrap)
+	_, apply := handler.(ProxyApplyTrap)
+	_, construct := handler.(ProxyConstructTrap)
+	return map[string]bool{
+		"get":            get,
+		"set":            set,
+		"has":            has,
+		"deleteProperty": deleteProperty,
+		"ownKeys":        ownKeys,
+		"apply":          apply,
+		"construct":      construct,
+	}
+}
+
+var goProxyHandlerSeq int32
+
+// Returns the ID and Go handler stored in a handler object created by NewProxyFunc, or 0 and
+// nil if it's not one or the handler has been released.
+func goProxyHandlerOf(val *Value) (int32, ProxyHandler) {
+	if val == nil || !val.IsObject() {
+		return 0, nil
+	}
+	obj, _ := val.AsObject()
+	if obj.InternalFieldCount() != 1 {
+		return 0, nil
+	}
+	id := obj.GetInternalField(0)
+	if !id.IsInt32() {
+		return 0, nil
+	}
+	if h, ok := val.ctx.proxyHandlers[id.Int32()]; ok {
+		return id.Int32(), h
+	}
+	return 0, nil
+}
+
+// A proxy trap implemented in Go; args are the JavaScript arguments after the target.
+type proxyTrap func(ctx *Context, handler ProxyHandler, target *Object, args []*Value) (interface{}, error)
+
+// Returns the ObjectTemplate for handlers created by NewProxyFunc, creating it and the trap
+// FunctionTemplates on first use. The handler's ID is stored in internal field 0.
+func (i *Isolate) proxyHandlerTemplate() *ObjectTemplate {
+	if i.proxyHandlerTmpl == nil {
+		traps := map[string]proxyTrap{
+			"get": func(ctx *Context, h ProxyHandler, target *Object, args []*Value) (interface{}, error) {
+				return h.(ProxyGetTrap).Get(target, args[0], args[1])
+			},
+			"set": func(ctx *Context, h ProxyHandler, target *Object, args []*Value) (interface{}, error) {
+				return h.(ProxySetTrap).Set(target, args[0], args[1], args[2])
+			},
+			"has": func(ctx *Context, h ProxyHandler, target *Object, args []*Value) (interface{}, error) {
+				return h.(ProxyHasTrap).Has(target, args[0])
+			},
+			"deleteProperty": func(ctx *Context, h ProxyHandler, target *Object, args []*Value) (interface{}, error) {
+				return h.(ProxyDeletePropertyTrap).DeleteProperty(target, args[0])
+			},
+			"ownKeys": func(ctx *Context, h ProxyHandler, target *Object, args []*Value) (interface{}, error) {
+				keys, err := h.(ProxyOwnKeysTrap).OwnKeys(target)
+				if err != nil {
+					return nil, err
+				}
+				arr := ctx.NewArray(len(keys))
+				for i, key := range keys {
+					if err = arr.SetIdx(uint32(i), key); err != nil {
+						return nil, err
+					}
+				}
+				return arr, nil
+			},
+			"apply": func(ctx *Context, h ProxyHandler, target *Object, args []*Value) (interface{}, error) {
+				callArgs, err := arrayElements(args[1])
+				if err != nil {
+					return nil, err
+				}
+				return h.(ProxyApplyTrap).Apply(&Function{Value: target.Value}, args[0], callArgs)
+			},
+			"construct": func(ctx *Context, h ProxyHandler, target *Object, args []*Value) (interface{}, error) {
+				ctorArgs, err := arrayElements(args[0])
+				if err != nil {
+					return nil, err
+				}
+				obj, err := h.(ProxyConstructTrap).Construct(&Function{Value: target.Value}, ctorArgs, args[1])
+				if err == nil && obj == nil {
+					err = errors.New("TypeError: proxy construct trap must return an object")
+				}
+				return obj, err
+			},
+		}
+		nArgs := map[string]int{"get": 2, "set": 3, "has": 1, "deleteProperty": 1,
+			"ownKeys": 0, "apply": 2, "construct": 2}
+
+		i.proxyTrapTmpls = make(map[string]*FunctionTemplate, len(traps))
+		for name, trap := range traps {
+			trap, n := trap, nArgs[name]
+			i.proxyTrapTmpls[name] = NewFunctionTemplate(i, func(info *FunctionCallbackInfo) *Value {
+				ctx := info.Context()
+				_, handler := goProxyHandlerOf(info.This().Value)
+				args := info.Args()
+				if handler == nil || len(args) < n+1 || !args[0].IsObject() {
+					return throwError(ctx, TypeError, "proxy handler has been released")
+				}
+				target, _ := args[0].AsObject()
+				result, err := trap(ctx, handler, target, args[1:n+1])
+				if err != nil {
+					return throwJSError(ctx, err)
+				}
+				val, err := ctx.NewValue(result)
+				if err != nil {
+					return throwJSError(ctx, err)
+				}
+				return val
+			})
+		}
+
+		tmpl := NewObjectTemplate(i)
+		tmpl.SetInternalFieldCount(1)
+		i.proxyHandlerTmpl = tmpl
+	}
+	return i.proxyHandlerTmpl
+}
+
+// Returns the elements of a JavaScript array.
+func arrayElements(val *Value) ([]*Value, error) {
+	obj, err := val.AsObject()
+	if err != nil {
+		return nil, err
+	}
+	arr := &Array{*obj}
+	n := arr.Length()
+	elements := make([]*Value, n)
+	for i := uint32(0); i < n; i++ {
+		if elements[i], err = arr.GetIdx(i); err != nil {
+			return nil, err
+		}
+	}
+	return elements, nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestNewProxy(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	target := ctx.NewObject()
+	target.Set("a", 1)
+	val, err := ctx.RunScript("({get(t, key) { return key in t ? t[key] : 'missing ' + key }})", "")
+	fatalIf(t, err)
+	handler, _ := val.AsObject()
+
+	proxy, err := v8.NewProxy(ctx, target, handler)
+	fatalIf(t, err)
+	if !proxy.IsProxy() || proxy.IsRevoked() {
+		t.Fatal("expected an unrevoked Proxy")
+	}
+	if !proxy.Target().SameValue(target.Value) || !proxy.Handler().SameValue(handler.Value) {
+		t.Error("expected Target and Handler to return the objects passed in")
+	}
+	ctx.Global().Set("p", proxy)
+	if result, _ := ctx.RunScript("p.a + ',' + p.b", ""); result.String() != "1,missing b" {
+		t.Errorf("unexpected result %q", result)
+	}
+	if p, err := ctx.Global().Get("p"); err != nil {
+		t.Error(err)
+	} else if _, err = p.AsProxy(); err != nil {
+		t.Errorf("expected AsProxy to succeed: %v", err)
+	}
+
+	proxy.Revoke()
+	if !proxy.IsRevoked() || !proxy.Target().IsNull() || !proxy.Handler().IsNull() {
+		t.Error("expected revoked Proxy to have a null target and handler")
+	}
+	if _, err := ctx.RunScript("p.a", ""); err == nil {
+		t.Error("expected error using a revoked Proxy")
+	}
+	if _, err := target.AsProxy(); err == nil {
+		t.Error("expected error calling AsProxy on a plain object")
+	}
+}
+
+// A handler that hides properties starting with "_" and logs calls.
+type hidingHandler struct {
+	iso   *v8.Isolate
+	calls []string
+}
+
+func (h *hidingHandler) Get(target *v8.Object, key *v8.Value, receiver *v8.Value) (*v8.Value, error) {
+	h.calls = append(h.calls, "get "+key.String())
+	if strings.HasPrefix(key.String(), "_") {
+		return nil, nil
+	}
+	return target.Get(key.String())
+}
+
+func (h *hidingHandler) Set(target *v8.Object, key *v8.Value, value *v8.Value, receiver *v8.Value) (bool, error) {
+	if strings.HasPrefix(key.String(), "_") {
+		return false, errors.New("TypeError: read-only")
+	}
+	return true, target.Set(key.String(), value)
+}
+
+func (h *hidingHandler) Has(target *v8.Object, key *v8.Value) (bool, error) {
+	return !strings.HasPrefix(key.String(), "_") && target.Has(key.String()), nil
+}
+
+func (h *hidingHandler) DeleteProperty(target *v8.Object, key *v8.Value) (bool, error) {
+	return false, nil
+}
+
+func (h *hidingHandler) OwnKeys(target *v8.Object) ([]*v8.Value, error) {
+	key, _ := v8.NewValue(h.iso, "visible")
+	return []*v8.Value{key}, nil
+}
+
+func TestNewProxyFunc(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	target, err := ctx.RunScript("({visible: 1, _secret: 2})", "")
+	fatalIf(t, err)
+	targetObj, _ := target.AsObject()
+	handler := &hidingHandler{iso: iso}
+	proxy, err := v8.NewProxyFunc(ctx, targetObj, handler)
+	fatalIf(t, err)
+	ctx.Global().Set("p", proxy)
+
+	tests := [...]struct {
+		source   string
+		expected string
+	}{
+		{"p.visible", "1"},
+		{"p._secret", "undefined"},
+		{"'visible' in p", "true"},
+		{"'_secret' in p", "false"},
+		{"p.added = 3; p.added", "3"},
+		{"delete p.visible", "false"},
+		{"Reflect.ownKeys(p).join()", "visible"},
+		{"try { p._secret = 0 } catch (e) { e.name + ': ' + e.message }", "TypeError: read-only"},
+	}
+	for _, tt := range tests {
+		result, err := ctx.RunScript(tt.source, "")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.source, err)
+		} else if result.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.source, tt.expected, result)
+		}
+	}
+	if len(handler.calls) == 0 || handler.calls[0] != "get visible" {
+		t.Errorf("unexpected get calls %v", handler.calls)
+	}
+
+	proxy.Revoke()
+	if _, err := ctx.RunScript("p.visible", ""); err == nil {
+		t.Error("expected error using a revoked Proxy")
+	}
+}
+
+func TestNewProxyFuncReleasedByGC(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	ctx.WithTemporaryValues(func() {
+		for i := 0; i < 10; i++ {
+			target, err := ctx.RunScript("({})", "")
+			fatalIf(t, err)
+			targetObj, _ := target.AsObject()
+			_, err = v8.NewProxyFunc(ctx, targetObj, &hidingHandler{iso: iso})
+			fatalIf(t, err)
+		}
+	})
+	if n := v8.ProxyHandlerCount(ctx); n != 10 {
+		t.Fatalf("expected 10 handlers, got %d", n)
+	}
+	iso.CollectGarbage()
+	if n := v8.ProxyHandlerCount(ctx); n != 0 {
+		t.Errorf("expected the handlers of unreachable Proxies to be released, %d remain", n)
+	}
+}
+
+type callHandler struct {
+	iso *v8.Isolate
+}
+
+func (h callHandler) Apply(target *v8.Function, this *v8.Value, args []*v8.Value) (*v8.Value, error) {
+	result, err := target.Call(this, args[0], args[0])
+	if err != nil {
+		return nil, err
+	}
+	return v8.NewValue(h.iso, "wrapped "+result.String())
+}
+
+func (callHandler) Construct(target *v8.Function, args []*v8.Value, newTarget *v8.Value) (*v8.Object, error) {
+	if len(args) == 0 {
+		return nil, errors.New("RangeError: need an argument")
+	}
+	return target.NewInstance(args[0])
+}
+
+func TestNewProxyFuncCallable(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	fn, err := ctx.RunScript("(function Box(a, b) { if (new.target) this.v = a; else return a + (b||0) })", "")
+	fatalIf(t, err)
+	fnObj, _ := fn.AsObject()
+	proxy, err := v8.NewProxyFunc(ctx, fnObj, callHandler{iso})
+	fatalIf(t, err)
+	ctx.Global().Set("p", proxy)
+
+	if result, err := ctx.RunScript("p(2)", ""); err != nil || result.String() != "wrapped 4" {
+		t.Errorf("expected wrapped 4, got %v, %v", result, err)
+	}
+	if result, err := ctx.RunScript("new p(7).v", ""); err != nil || result.Int32() != 7 {
+		t.Errorf("expected 7, got %v, %v", result, err)
+	}
+	_, err = ctx.RunScript("new p()", "")
+	if jsErr, ok := err.(*v8.JSError); !ok || !strings.HasPrefix(jsErr.Message, "RangeError") {
+		t.Errorf("expected RangeError, got %v", err)
+	}
+}
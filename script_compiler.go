@@ -4,8 +4,14 @@
 
 package v8go
 
+// #include <stdlib.h>
 // #include "v8go.h"
 import "C"
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+)
 
 type CompileMode C.int
 
@@ -18,3 +24,63 @@ type CompilerCachedData struct {
 	Bytes    []byte
 	Rejected bool
 }
+
+// ScriptOrigin describes where a script's source code came from; it's used in stack traces
+// and by debuggers.
+type ScriptOrigin struct {
+	ResourceName string // The script's file name or URL
+	LineOffset   int    // Zero-based line of the resource at which the script starts
+	ColumnOffset int    // Zero-based column of the resource at which the script starts
+	SourceMapURL string // URL of the script's source map, if any
+	IsShared     bool   // True if the script is shared cross-origin
+	IsOpaque     bool   // True if the script's details are hidden from error reporting
+	IsModule     bool   // True if the script is an ES module (not yet supported for compiling)
+
+	// Primitive values that are attached to the script for the embedder's use.
+	HostDefinedOptions []*Value
+}
+
+// Converts a ScriptOrigin to C parameters, or returns zero parameters if it's nil.
+// The returned function must be called after the parameters have been used, to free them.
+func (o *ScriptOrigin) toC() (C.ScriptOriginParams, func(), error) {
+	var params C.ScriptOriginParams
+	if o == nil {
+		return params, func() {}, nil
+	}
+	if o.IsModule {
+		return params, nil, errors.New("v8go: compiling modules is not supported")
+	}
+	hostOptions := make([]C.ValuePtr, len(o.HostDefinedOptions))
+	for i, opt := range o.HostDefinedOptions {
+		if opt == nil || opt.IsObject() {
+			return params, nil, errors.New("v8go: host-defined options must be primitive values")
+		}
+		hostOptions[i] = opt.valuePtr()
+	}
+	if len(hostOptions) > 0 {
+		params.hostDefinedOptionsCount = C.int(len(hostOptions))
+		params.hostDefinedOptions = &hostOptions[0]
+	}
+	if o.ResourceName != "" {
+		params.resourceName = C.CString(o.ResourceName)
+		params.resourceNameLen = C.int(len(o.ResourceName))
+	}
+	if o.SourceMapURL != "" {
+		params.sourceMapUrl = C.CString(o.SourceMapURL)
+		params.sourceMapUrlLen = C.int(len(o.SourceMapURL))
+	}
+	params.lineOffset = C.int(o.LineOffset)
+	params.columnOffset = C.int(o.ColumnOffset)
+	if o.IsShared {
+		params.isShared = 1
+	}
+	if o.IsOpaque {
+		params.isOpaque = 1
+	}
+	free := func() {
+		C.free(unsafe.Pointer(params.resourceName))
+		C.free(unsafe.Pointer(params.sourceMapUrl))
+		runtime.KeepAlive(hostOptions)
+	}
+	return params, free, nil
+}
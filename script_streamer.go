@@ -0,0 +1,119 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include <stdlib.h>
+// #include "v8go.h"
+import "C"
+import (
+	"io"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// ScriptStreamer compiles a script on a background goroutine while its source is read from an
+// io.Reader, so that the Isolate stays responsive while a large script is compiled.
+// Create one with Isolate.StartStreamingScript, then call Finish to get the UnboundScript.
+type ScriptStreamer struct {
+	ptr     C.ScriptStreamerPtr
+	iso     *Isolate
+	origin  string
+	opts    CompileOptions
+	reader  io.Reader
+	source  []byte        // The source read so far, if opts.RetainSource
+	readErr error         // Error returned by the reader, if any
+	handle  cgo.Handle    // Handle pointing to this ScriptStreamer, passed to C++
+	done    chan struct{} // Closed when background compilation is done
+}
+
+// StartStreamingScript starts compiling a script whose source is read from r, on a new
+// goroutine; the reader is called from that goroutine. The source must be UTF-8.
+// origin (a.k.a. filename) and opts are as for CompileUnboundScript. If the options contain
+// CachedData, the source is read and the code cache is deserialized in the background instead.
+// The Isolate's CodeCache isn't used, since the source isn't known until it's been compiled.
+//
+// Finish must be called on the returned ScriptStreamer, and the Isolate must not be disposed
+// until it has been.
+func (i *Isolate) StartStreamingScript(r io.Reader, origin string, opts CompileOptions) (*ScriptStreamer, error) {
+	cOptions, free, err := opts.toC()
+	if err != nil {
+		return nil, err
+	}
+	s := &ScriptStreamer{
+		iso:    i,
+		origin: origin,
+		opts:   opts,
+		reader: r,
+		done:   make(chan struct{}),
+	}
+	s.handle = cgo.NewHandle(s)
+	s.ptr = C.NewScriptStreamer(i.ptr, C.uintptr_t(s.handle), cOptions)
+	free()
+	go func() {
+		C.ScriptStreamerRun(s.ptr)
+		close(s.done)
+	}()
+	return s, nil
+}
+
+// Done returns a channel that's closed when the background work is finished, after which
+// Finish won't block.
+func (s *ScriptStreamer) Done() <-chan struct{} {
+	return s.done
+}
+
+// Finish waits for the background work to finish, then completes the compilation and returns
+// the UnboundScript. It must be called on the Isolate's goroutine, only once.
+// error will be the reader's error, if it failed, or else of type `JSError` if not nil.
+func (s *ScriptStreamer) Finish() (*UnboundScript, error) {
+	<-s.done
+	defer func() {
+		C.ScriptStreamerFree(s.ptr)
+		s.ptr = nil
+		s.handle.Delete()
+	}()
+	if s.readErr != nil {
+		return nil, s.readErr
+	}
+
+	cOptions, free, err := s.opts.toC()
+	if err != nil {
+		return nil, err
+	}
+	defer free()
+	cOrigin := C.CString(s.origin)
+	defer C.free(unsafe.Pointer(cOrigin))
+
+	s.iso.freeFinalizedScripts()
+	rtn := C.ScriptStreamerFinish(s.ptr, cOrigin, C.int(len(s.origin)), cOptions)
+	if rtn.ptr == nil {
+		return nil, newJSError(rtn.error)
+	}
+	if s.opts.CachedData != nil {
+		s.opts.CachedData.Rejected = int(rtn.cachedDataRejected) == 1
+	}
+	return newUnboundScript(s.iso, rtn.ptr, string(s.source)), nil
+}
+
+//export goReadScriptSource
+func goReadScriptSource(handle C.uintptr_t, buf unsafe.Pointer, size C.size_t) C.size_t {
+	// Reads the next chunk of source into the C buffer, returning 0 at EOF or on error.
+	s := cgo.Handle(handle).Value().(*ScriptStreamer)
+	dst := (*[1 << 30]byte)(buf)[:size:size]
+	for {
+		n, err := s.reader.Read(dst)
+		if n > 0 {
+			if s.opts.RetainSource {
+				s.source = append(s.source, dst[:n]...)
+			}
+			return C.size_t(n)
+		} else if err == io.EOF {
+			return 0
+		} else if err != nil {
+			s.readErr = err
+			return 0
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+// Returns a script big enough to be read in several chunks, whose result is "done".
+func bigScript() string {
+	var sb strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&sb, "function f%d(x) { return x + %d; }\n", i, i)
+	}
+	sb.WriteString("f4999(1) === 5000 ? 'done' : 'wrong'")
+	return sb.String()
+}
+
+func TestStreamingScript(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	source := bigScript()
+	streamer, err := iso.StartStreamingScript(strings.NewReader(source), "big.js", v8.CompileOptions{RetainSource: true})
+	fatalIf(t, err)
+	// The isolate can be used while the script compiles:
+	if val, err := ctx.RunScript("1 + 1", ""); err != nil || val.Int32() != 2 {
+		t.Errorf("expected 2, got %v, %v", val, err)
+	}
+	<-streamer.Done()
+	script, err := streamer.Finish()
+	fatalIf(t, err)
+	if script.Source() != source {
+		t.Error("expected the script's Source to be what was read")
+	}
+	val, err := script.Run(ctx)
+	fatalIf(t, err)
+	if val.String() != "done" {
+		t.Errorf("expected done, got %q", val)
+	}
+
+	// With a code cache:
+	cache := script.CreateCodeCache()
+	iso2 := v8.NewIsolate()
+	defer iso2.Dispose()
+	ctx2 := v8.NewContext(iso2)
+	defer ctx2.Close()
+	streamer, err = iso2.StartStreamingScript(iotest.HalfReader(strings.NewReader(source)), "big.js",
+		v8.CompileOptions{CachedData: cache})
+	fatalIf(t, err)
+	script, err = streamer.Finish()
+	fatalIf(t, err)
+	if cache.Rejected {
+		t.Error("expected code cache to be accepted")
+	}
+	if val, err = script.Run(ctx2); err != nil || val.String() != "done" {
+		t.Errorf("expected done, got %v, %v", val, err)
+	}
+}
+
+func TestStreamingScriptErrors(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+
+	streamer, err := iso.StartStreamingScript(strings.NewReader("function ("), "bad.js", v8.CompileOptions{})
+	fatalIf(t, err)
+	if _, err = streamer.Finish(); err == nil {
+		t.Error("expected syntax error")
+	} else if _, ok := err.(*v8.JSError); !ok {
+		t.Errorf("expected a JSError, got %T", err)
+	}
+
+	readErr := errors.New("disk on fire")
+	reader := iotest.TimeoutReader(strings.NewReader(bigScript()))
+	streamer, err = iso.StartStreamingScript(reader, "broken.js", v8.CompileOptions{})
+	fatalIf(t, err)
+	if _, err = streamer.Finish(); err != iotest.ErrTimeout {
+		t.Errorf("expected the reader's error, got %v", err)
+	}
+
+	streamer, err = iso.StartStreamingScript(iotest.ErrReader(readErr), "broken.js", v8.CompileOptions{})
+	fatalIf(t, err)
+	if _, err = streamer.Finish(); err != readErr {
+		t.Errorf("expected the reader's error, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+#include "v8go.hh"
+
+
+/********** V8GoSerializedValue **********/
+
+namespace v8go {
+
+  // The output of ValueSerializer, plus the memory of the ArrayBuffers transferred with it
+  // and the SharedArrayBuffers shared with it, which can't be represented as bytes.
+  struct V8GoSerializedValue {
+    std::pair<uint8_t*, size_t> data {nullptr, 0};
+    std::vector<std::shared_ptr<BackingStore>> arrayBuffers;
+    std::vector<std::shared_ptr<BackingStore>> sharedArrayBuffers;
+
+    ~V8GoSerializedValue() {
+      free(data.first); // (ValueSerializer allocates with realloc)
+    }
+  };
+
+
+  class SerializerDelegate : public ValueSerializer::Delegate {
+  public:
+    SerializerDelegate(V8GoContext* ctx, V8GoSerializedValue* ser, uintptr_t hostObjectHandle)
+    :_ctx(ctx)
+    ,_ser(ser)
+    ,_hostObjectHandle(hostObjectHandle)
+    { }
+
+    void setSerializer(ValueSerializer* serializer)     {_serializer = serializer;}
+
+    void ThrowDataCloneError(Local<String> message) override {
+      _ctx->iso->ThrowException(Exception::Error(message));
+    }
+
+    // Called for objects with internal fields, i.e. instances of ObjectTemplates.
+    Maybe<bool> WriteHostObject(Isolate* iso, Local<Object> object) override {
+      if (!_hostObjectHandle) {
+        return ValueSerializer::Delegate::WriteHostObject(iso, object);
+      }
+      RtnHostObject rtn = goWriteHostObject(_hostObjectHandle, _ctx->goRef,
+                                            _ctx->addValue(object));
+      if (rtn.error) {
+        iso->ThrowException(Exception::Error(String::NewFromUtf8(iso, rtn.error).ToLocalChecked()));
+        free(rtn.error);
+        return Nothing<bool>();
+      }
+      _serializer->WriteUint32(uint32_t(rtn.length));
+      _serializer->WriteRawBytes(rtn.data, rtn.length);
+      free(rtn.data);
+      return Just(true);
+    }
+
+    Maybe<uint32_t> GetSharedArrayBufferId(Isolate* iso,
+                                           Local<SharedArrayBuffer> buffer) override {
+      if (!_ser) {
+        // Serializing to bytes, which can't share memory:
+        return ValueSerializer::Delegate::GetSharedArrayBufferId(iso, buffer);
+      }
+      _ser->sharedArrayBuffers.push_back(buffer->GetBackingStore());
+      return Just(uint32_t(_ser->sharedArrayBuffers.size() - 1));
+    }
+
+  private:
+    V8GoContext* const _ctx;
+    V8GoSerializedValue* const _ser;
+    uintptr_t const _hostObjectHandle;
+    ValueSerializer* _serializer = nullptr;
+  };
+
+
+  class DeserializerDelegate : public ValueDeserializer::Delegate {
+  public:
+    DeserializerDelegate(V8GoContext* ctx, V8GoSerializedValue* ser, uintptr_t hostObjectHandle)
+    :_ctx(ctx)
+    ,_ser(ser)
+    ,_hostObjectHandle(hostObjectHandle)
+    { }
+
+    void setDeserializer(ValueDeserializer* deserializer) {_deserializer = deserializer;}
+
+    MaybeLocal<Object> ReadHostObject(Isolate* iso) override {
+      if (!_hostObjectHandle) {
+        return ValueDeserializer::Delegate::ReadHostObject(iso);
+      }
+      uint32_t length;
+      const void* data;
+      if (!_deserializer->ReadUint32(&length) || !_deserializer->ReadRawBytes(length, &data)) {
+        iso->ThrowException(Exception::Error(
+            String::NewFromUtf8Literal(iso, "Invalid host object data")));
+        return {};
+      }
+      RtnValue rtn = goReadHostObject(_hostObjectHandle, _ctx->goRef,
+                                      const_cast<void*>(data), length);
+      if (rtn.error.msg) {
+        iso->ThrowException(Exception::Error(String::NewFromUtf8(iso, rtn.error.msg).ToLocalChecked()));
+        free((void*)rtn.error.msg);
+        return {};
+      }
+      return _ctx->getValue(rtn.value).As<Object>();
+    }
+
+    MaybeLocal<SharedArrayBuffer> GetSharedArrayBufferFromId(Isolate* iso,
+                                                             uint32_t id) override {
+      if (!_ser || id >= _ser->sharedArrayBuffers.size()) {
+        iso->ThrowException(Exception::Error(
+            String::NewFromUtf8Literal(iso, "Invalid SharedArrayBuffer ID")));
+        return {};
+      }
+      return SharedArrayBuffer::New(iso, _ser->sharedArrayBuffers[id]);
+    }
+
+  private:
+    V8GoContext* const _ctx;
+    V8GoSerializedValue* const _ser;
+    uintptr_t const _hostObjectHandle;
+    ValueDeserializer* _deserializer = nullptr;
+  };
+
+}
+
+
+/********** ValueSerializer **********/
+
+RtnSerializedValue SerializeValue(ValuePtr ptr, int transferCount, ValuePtr transfer[],
+                                  Bool takeBuffers, uintptr_t hostObjectHandle) {
+  WithValue _with(ptr);
+  Isolate* iso = _with.iso();
+  RtnSerializedValue rtn = {};
+
+  auto ser = std::make_unique<V8GoSerializedValue>();
+  SerializerDelegate delegate(_with.ctx, takeBuffers ? ser.get() : nullptr, hostObjectHandle);
+  ValueSerializer serializer(iso, &delegate);
+  delegate.setSerializer(&serializer);
+
+  std::vector<Local<ArrayBuffer>> transferred;
+  for (int i = 0; i < transferCount; ++i) {
+    Local<Value> item = Deref(transfer[i]);
+    const char* problem = nullptr;
+    if (!item->IsArrayBuffer() || !item.As<ArrayBuffer>()->IsDetachable()) {
+      problem = "Only detachable ArrayBuffers can be transferred";
+    } else {
+      for (auto& buffer : transferred) {
+        if (buffer->StrictEquals(item)) {
+          problem = "ArrayBuffer appears more than once in the transfer list";
+        }
+      }
+    }
+    if (problem) {
+      iso->ThrowException(Exception::TypeError(_with.makeString(problem)));
+      rtn.error = _with.exceptionError();
+      return rtn;
+    }
+    Local<ArrayBuffer> buffer = item.As<ArrayBuffer>();
+    serializer.TransferArrayBuffer(uint32_t(transferred.size()), buffer);
+    transferred.push_back(buffer);
+  }
+
+  serializer.WriteHeader();
+  if (serializer.WriteValue(_with.local_ctx, _with.value).IsNothing()) {
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  ser->data = serializer.Release();
+
+  if (takeBuffers) {
+    // Transferring takes the memory away from the sender:
+    for (auto& buffer : transferred) {
+      ser->arrayBuffers.push_back(buffer->GetBackingStore());
+      buffer->Detach();
+    }
+  }
+  rtn.ptr = ser.release();
+  return rtn;
+}
+
+const void* SerializedValueData(SerializedValuePtr ser, size_t* length) {
+  *length = ser->data.second;
+  return ser->data.first;
+}
+
+static RtnValue deserialize(WithContext &_with, const uint8_t* data, size_t length,
+                            std::vector<Local<ArrayBuffer>> const& transferred,
+                            V8GoSerializedValue* ser, uintptr_t hostObjectHandle)
+{
+  DeserializerDelegate delegate(_with.ctx, ser, hostObjectHandle);
+  ValueDeserializer deserializer(_with.iso(), data, length, &delegate);
+  delegate.setDeserializer(&deserializer);
+  for (size_t i = 0; i < transferred.size(); ++i) {
+    deserializer.TransferArrayBuffer(uint32_t(i), transferred[i]);
+  }
+  if (deserializer.ReadHeader(_with.local_ctx).IsNothing()) {
+    RtnValue rtn = {};
+    rtn.error = _with.exceptionError();
+    return rtn;
+  }
+  return _with.returnValue(deserializer.ReadValue(_with.local_ctx));
+}
+
+RtnValue DeserializeValue(ContextPtr ctx, SerializedValuePtr ser) {
+  WithContext _with(ctx);
+  std::vector<Local<ArrayBuffer>> transferred;
+  for (auto& store : ser->arrayBuffers) {
+    transferred.push_back(ArrayBuffer::New(_with.iso(), store));
+  }
+  return deserialize(_with, ser->data.first, ser->data.second, transferred, ser, 0);
+}
+
+RtnValue DeserializeBytes(ContextPtr ctx, const void* data, size_t length,
+                          int transferCount, ValuePtr transfer[], uintptr_t hostObjectHandle) {
+  WithContext _with(ctx);
+  std::vector<Local<ArrayBuffer>> transferred;
+  for (int i = 0; i < transferCount; ++i) {
+    transferred.push_back(Deref(transfer[i]).As<ArrayBuffer>());
+  }
+  return deserialize(_with, static_cast<const uint8_t*>(data), length, transferred,
+                     nullptr, hostObjectHandle);
+}
+
+void SerializedValueFree(SerializedValuePtr ser) {
+  delete ser;
+}
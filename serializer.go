@@ -0,0 +1,206 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include <stdlib.h>
+// #include "v8go.h"
+import "C"
+import (
+	"errors"
+	"math"
+	"runtime"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// HostObjectSerializer writes "host objects" for SerializeWithOptions. Host objects are
+// objects with internal fields, i.e. instances of an ObjectTemplate with a nonzero
+// InternalFieldCount; these usually wrap Go values, which V8 can't serialize by itself.
+type HostObjectSerializer interface {
+	// SerializeHostObject returns data from which DeserializeHostObject can recreate obj.
+	// Returning an error makes the serialization fail with that message.
+	SerializeHostObject(obj *Object) ([]byte, error)
+}
+
+// HostObjectDeserializer recreates host objects for DeserializeWithOptions.
+type HostObjectDeserializer interface {
+	// DeserializeHostObject returns a new object in ctx, made from the data returned by
+	// SerializeHostObject.
+	DeserializeHostObject(ctx *Context, data []byte) (*Object, error)
+}
+
+// SerializeOptions are the options for SerializeWithOptions.
+type SerializeOptions struct {
+	// ArrayBuffers whose contents are not copied into the serialized data; instead they're
+	// referred to by their index in this list. The same number of ArrayBuffers must be given
+	// in DeserializeOptions.Transfer to stand in for them. (See ArrayBuffer.Transfer.)
+	Transfer []*ArrayBuffer
+
+	// Writes host objects; if nil, serializing a host object fails.
+	HostObjects HostObjectSerializer
+}
+
+// DeserializeOptions are the options for DeserializeWithOptions.
+type DeserializeOptions struct {
+	// The ArrayBuffers that replace the ones in SerializeOptions.Transfer, in the same order.
+	Transfer []*ArrayBuffer
+
+	// Reads host objects; if nil, deserializing a host object fails.
+	HostObjects HostObjectDeserializer
+}
+
+// Serialize encodes a value with V8's ValueSerializer, the structured clone algorithm that
+// `postMessage` and IndexedDB use. Unlike JSON, this preserves Map, Set, Date, RegExp, BigInt,
+// typed arrays, undefined, and cyclic or shared references. It fails on functions, symbols,
+// SharedArrayBuffers, and host objects. The data can be decoded by Deserialize in any Context.
+func Serialize(val Valuer) ([]byte, error) {
+	return SerializeWithOptions(val, SerializeOptions{})
+}
+
+// SerializeWithOptions is like Serialize, with support for ArrayBuffer transfer and
+// host objects.
+func SerializeWithOptions(val Valuer, opts SerializeOptions) ([]byte, error) {
+	var handle cgo.Handle
+	if opts.HostObjects != nil {
+		handle = cgo.NewHandle(opts.HostObjects)
+		defer handle.Delete()
+	}
+	ser, err := serialize(val.value(), transferValues(opts.Transfer), false, handle)
+	if err != nil {
+		return nil, err
+	}
+	defer ser.free()
+	var length C.size_t
+	data := C.SerializedValueData(ser.ptr, &length)
+	return goBytes(data, length)
+}
+
+// Copies serialized data to a Go slice. C.GoBytes takes a 32-bit length, so larger data is
+// rejected instead of being truncated.
+func goBytes(data unsafe.Pointer, length C.size_t) ([]byte, error) {
+	if length > math.MaxInt32 {
+		return nil, errors.New("v8go: serialized data of 2GB or more is not supported")
+	}
+	return C.GoBytes(data, C.int(length)), nil
+}
+
+// Deserialize decodes data produced by Serialize, creating the value in the given Context.
+func Deserialize(ctx *Context, data []byte) (*Value, error) {
+	return DeserializeWithOptions(ctx, data, DeserializeOptions{})
+}
+
+// DeserializeWithOptions is like Deserialize, with support for ArrayBuffer transfer and
+// host objects.
+func DeserializeWithOptions(ctx *Context, data []byte, opts DeserializeOptions) (*Value, error) {
+	if ctx == nil {
+		return nil, errors.New("v8go: Context is required")
+	}
+	var dataPtr unsafe.Pointer
+	if len(data) > 0 {
+		dataPtr = unsafe.Pointer(&data[0])
+	}
+	cTransfer, transferPtr := valuePtrs(transferValues(opts.Transfer))
+	var handle cgo.Handle
+	if opts.HostObjects != nil {
+		handle = cgo.NewHandle(opts.HostObjects)
+		defer handle.Delete()
+	}
+	rtn := C.DeserializeBytes(ctx.ptr, dataPtr, C.size_t(len(data)),
+		C.int(len(cTransfer)), transferPtr, C.uintptr_t(handle))
+	runtime.KeepAlive(data)
+	runtime.KeepAlive(cTransfer)
+	return valueResult(ctx, rtn)
+}
+
+//export goWriteHostObject
+func goWriteHostObject(handle C.uintptr_t, ctxHandle C.uintptr_t, obj C.ValueRef) C.RtnHostObject {
+	ctx := contextFromHandle(ctxHandle)
+	hostObjects := cgo.Handle(handle).Value().(HostObjectSerializer)
+	data, err := hostObjects.SerializeHostObject(&Object{&Value{obj, ctx}})
+	if err != nil {
+		return C.RtnHostObject{error: C.CString(err.Error())}
+	}
+	return C.RtnHostObject{data: C.CBytes(data), length: C.size_t(len(data))}
+}
+
+//export goReadHostObject
+func goReadHostObject(handle C.uintptr_t, ctxHandle C.uintptr_t, data unsafe.Pointer, length C.size_t) C.RtnValue {
+	ctx := contextFromHandle(ctxHandle)
+	hostObjects := cgo.Handle(handle).Value().(HostObjectDeserializer)
+	bytes, err := goBytes(data, length)
+	var obj *Object
+	if err == nil {
+		obj, err = hostObjects.DeserializeHostObject(ctx, bytes)
+	}
+	if err == nil && (obj == nil || obj.ctx != ctx) {
+		err = errors.New("v8go: DeserializeHostObject must return an Object in the given Context")
+	}
+	if err != nil {
+		return C.RtnValue{error: C.RtnError{msg: C.CString(err.Error())}}
+	}
+	return C.RtnValue{value: obj.ref}
+}
+
+// serializedValue is a Value encoded by V8's ValueSerializer, which can be decoded into any
+// Context of any Isolate. Unlike the []byte form, it can carry the memory of transferred
+// ArrayBuffers and SharedArrayBuffers along with it. It must be freed after use.
+type serializedValue struct {
+	ptr C.SerializedValuePtr
+}
+
+// Serializes a Value for deserialize. The ArrayBuffers in `transfer` are detached, and their
+// memory moves with the serialized value instead of being copied.
+func serializeValue(val *Value, transfer []*Value) (*serializedValue, error) {
+	return serialize(val, transfer, true, 0)
+}
+
+func serialize(val *Value, transfer []*Value, takeBuffers bool, hostObjects cgo.Handle) (*serializedValue, error) {
+	cTransfer, transferPtr := valuePtrs(transfer)
+	var take C.Bool
+	if takeBuffers {
+		take = 1
+	}
+	rtn := C.SerializeValue(val.valuePtr(), C.int(len(cTransfer)), transferPtr, take,
+		C.uintptr_t(hostObjects))
+	runtime.KeepAlive(cTransfer)
+	if rtn.ptr == nil {
+		return nil, newJSError(rtn.error)
+	}
+	return &serializedValue{rtn.ptr}, nil
+}
+
+// Reconstructs the serialized value in a Context. Can only be called once if any
+// ArrayBuffers were transferred.
+func (s *serializedValue) deserialize(ctx *Context) (*Value, error) {
+	rtn := C.DeserializeValue(ctx.ptr, s.ptr)
+	return valueResult(ctx, rtn)
+}
+
+func (s *serializedValue) free() {
+	if s.ptr != nil {
+		C.SerializedValueFree(s.ptr)
+		s.ptr = nil
+	}
+}
+
+func transferValues(buffers []*ArrayBuffer) []*Value {
+	vals := make([]*Value, len(buffers))
+	for i, buf := range buffers {
+		vals[i] = buf.Value
+	}
+	return vals
+}
+
+// Converts Values to a C array; the slice must be kept alive until the C call returns.
+func valuePtrs(vals []*Value) ([]C.ValuePtr, *C.ValuePtr) {
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	ptrs := make([]C.ValuePtr, len(vals))
+	for i, v := range vals {
+		ptrs[i] = v.valuePtr()
+	}
+	return ptrs, &ptrs[0]
+}
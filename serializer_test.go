@@ -0,0 +1,192 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestSerialize(t *testing.T) {
+	t.Parallel()
+	ctx1 := v8.NewContext()
+	defer ctx1.Isolate().Dispose()
+	defer ctx1.Close()
+	ctx2 := v8.NewContext()
+	defer ctx2.Isolate().Dispose()
+	defer ctx2.Close()
+
+	val, err := ctx1.RunScript(`
+		const obj = {
+			map: new Map([[1, "one"], ["two", 2]]),
+			set: new Set(["a", "b"]),
+			date: new Date(Date.UTC(2022, 2, 14)),
+			re: /ab+c/gi,
+			big: 12345678901234567890n,
+			bytes: new Uint16Array([1, 2, 300]),
+			undef: undefined,
+		};
+		obj.self = obj;
+		obj`, "")
+	fatalIf(t, err)
+	data, err := v8.Serialize(val)
+	fatalIf(t, err)
+	if len(data) == 0 {
+		t.Fatal("expected serialized data")
+	}
+
+	clone, err := v8.Deserialize(ctx2, data)
+	fatalIf(t, err)
+	fatalIf(t, ctx2.Global().Set("copy", clone))
+	result, err := ctx2.RunScript(`[
+		copy.map.get(1), copy.map.get("two"),
+		[...copy.set].join(),
+		copy.date.toISOString(),
+		copy.re.source + "/" + copy.re.flags,
+		String(copy.big),
+		copy.bytes instanceof Uint16Array ? copy.bytes.join() : "?",
+		"undef" in copy,
+		copy.self === copy,
+	].join(" ")`, "")
+	fatalIf(t, err)
+	const expected = "one 2 a,b 2022-03-14T00:00:00.000Z ab+c/gi 12345678901234567890 1,2,300 true true"
+	if s := result.String(); s != expected {
+		t.Errorf("unexpected result: %s", s)
+	}
+
+	for _, source := range []string{"(() => 1)", "Symbol('x')", "new SharedArrayBuffer(8)"} {
+		val, err := ctx1.RunScript(source, "")
+		fatalIf(t, err)
+		if _, err := v8.Serialize(val); err == nil {
+			t.Errorf("expected Serialize(%s) to fail", source)
+		}
+	}
+
+	if _, err := v8.Deserialize(ctx2, []byte{1, 2, 3}); err == nil {
+		t.Error("expected error deserializing garbage")
+	}
+	if _, err := v8.Deserialize(nil, data); err == nil {
+		t.Error("expected error with nil Context")
+	}
+}
+
+func TestSerializeTransfer(t *testing.T) {
+	t.Parallel()
+	ctx1 := v8.NewContext()
+	defer ctx1.Isolate().Dispose()
+	defer ctx1.Close()
+	ctx2 := v8.NewContext()
+	defer ctx2.Isolate().Dispose()
+	defer ctx2.Close()
+
+	buf, err := v8.NewArrayBuffer(ctx1, []byte("hello"))
+	fatalIf(t, err)
+	fatalIf(t, ctx1.Global().Set("buf", buf))
+	val, err := ctx1.RunScript("({buf, view: new Uint8Array(buf, 1, 3)})", "")
+	fatalIf(t, err)
+
+	data, err := v8.SerializeWithOptions(val, v8.SerializeOptions{Transfer: []*v8.ArrayBuffer{buf}})
+	fatalIf(t, err)
+	moved, err := buf.Transfer(ctx2)
+	fatalIf(t, err)
+	if buf.ByteLength() != 0 {
+		t.Errorf("expected source buffer to be detached")
+	}
+
+	clone, err := v8.DeserializeWithOptions(ctx2, data, v8.DeserializeOptions{Transfer: []*v8.ArrayBuffer{moved}})
+	fatalIf(t, err)
+	fatalIf(t, ctx2.Global().Set("copy", clone))
+	fatalIf(t, ctx2.Global().Set("moved", moved))
+	result, err := ctx2.RunScript(
+		"[copy.buf === moved, copy.view.buffer === moved, String.fromCharCode(...copy.view)].join()", "")
+	fatalIf(t, err)
+	if s := result.String(); s != "true,true,ell" {
+		t.Errorf("unexpected result: %s", s)
+	}
+
+	// Without the transferred buffers, deserialization fails:
+	if _, err := v8.Deserialize(ctx2, data); err == nil {
+		t.Error("expected error deserializing without the transfer list")
+	}
+}
+
+// Serializes instances of a template whose internal field holds a Go-side ID.
+type pointHostObjects struct {
+	tmpl *v8.ObjectTemplate
+}
+
+func (p pointHostObjects) SerializeHostObject(obj *v8.Object) ([]byte, error) {
+	id := obj.GetInternalField(0)
+	if !id.IsInt32() {
+		return nil, errors.New("not a point")
+	}
+	return []byte(strconv.Itoa(int(id.Int32()))), nil
+}
+
+func (p pointHostObjects) DeserializeHostObject(ctx *v8.Context, data []byte) (*v8.Object, error) {
+	id, err := strconv.Atoi(string(data))
+	if err != nil {
+		return nil, err
+	}
+	obj, err := p.tmpl.NewInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return obj, obj.SetInternalField(0, int32(id))
+}
+
+func TestSerializeHostObjects(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	tmpl := v8.NewObjectTemplate(iso)
+	tmpl.SetInternalFieldCount(1)
+	point, err := tmpl.NewInstance(ctx)
+	fatalIf(t, err)
+	fatalIf(t, point.SetInternalField(0, int32(77)))
+	fatalIf(t, ctx.Global().Set("point", point))
+	val, err := ctx.RunScript("[point, point]", "")
+	fatalIf(t, err)
+
+	if _, err := v8.Serialize(val); err == nil {
+		t.Error("expected host object to fail without HostObjects")
+	}
+
+	hostObjects := pointHostObjects{tmpl}
+	data, err := v8.SerializeWithOptions(val, v8.SerializeOptions{HostObjects: hostObjects})
+	fatalIf(t, err)
+	clone, err := v8.DeserializeWithOptions(ctx, data, v8.DeserializeOptions{HostObjects: hostObjects})
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("copy", clone))
+	same, err := ctx.RunScript("copy[0] === copy[1] && copy[0] !== point", "")
+	fatalIf(t, err)
+	if !same.Boolean() {
+		t.Error("expected one new host object referenced twice")
+	}
+	elem, err := ctx.RunScript("copy[0]", "")
+	fatalIf(t, err)
+	obj, err := elem.AsObject()
+	fatalIf(t, err)
+	if id := obj.GetInternalField(0).Int32(); id != 77 {
+		t.Errorf("unexpected internal field %d", id)
+	}
+
+	// Errors from the hooks propagate:
+	other := v8.NewObjectTemplate(iso)
+	other.SetInternalFieldCount(1)
+	bad, err := other.NewInstance(ctx)
+	fatalIf(t, err)
+	_, err = v8.SerializeWithOptions(bad, v8.SerializeOptions{HostObjects: hostObjects})
+	if err == nil || !strings.Contains(err.Error(), "not a point") {
+		t.Errorf("expected host object error, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+// #include "v8go.h"
+import "C"
+
+// Set is a JavaScript `Set`: a collection of unique values in insertion order.
+//
+// Set's Has and Delete methods operate on its elements, and hide the Object methods of the
+// same names, which operate on its properties.
+type Set struct {
+	*Object
+}
+
+// NewSet creates a new, empty Set.
+func NewSet(ctx *Context) *Set {
+	return &Set{&Object{&Value{C.NewSet(ctx.ptr), ctx}}}
+}
+
+// Add adds a value to the Set, if it's not already present.
+// The value may be a *Value or any Go type accepted by Context.NewValue.
+func (s *Set) Add(val interface{}) error {
+	v, err := s.ctx.NewValue(val)
+	if err != nil {
+		return err
+	}
+	_, err = valueResult(s.ctx, C.SetAdd(s.valuePtr(), v.valuePtr()))
+	return err
+}
+
+// Has returns true if the Set contains the value.
+func (s *Set) Has(val interface{}) (bool, error) {
+	v, err := s.ctx.NewValue(val)
+	if err != nil {
+		return false, err
+	}
+	return C.SetHas(s.valuePtr(), v.valuePtr()) != 0, nil
+}
+
+// Delete removes a value, returning true if it was present.
+func (s *Set) Delete(val interface{}) (bool, error) {
+	v, err := s.ctx.NewValue(val)
+	if err != nil {
+		return false, err
+	}
+	return C.SetDelete(s.valuePtr(), v.valuePtr()) != 0, nil
+}
+
+// Clear removes all values.
+func (s *Set) Clear() {
+	C.SetClear(s.valuePtr())
+}
+
+// Size returns the number of values.
+func (s *Set) Size() int {
+	return int(C.SetSize(s.valuePtr()))
+}
+
+// AsArray returns a new Array containing the Set's values.
+func (s *Set) AsArray() *Array {
+	return &Array{Object{&Value{C.SetAsArray(s.valuePtr()), s.ctx}}}
+}
+
+// ForEach calls the callback with each value, in insertion order. Changes made to the Set
+// during the iteration are not seen. If the callback returns an error, iteration stops and
+// ForEach returns that error.
+func (s *Set) ForEach(callback func(val *Value) error) error {
+	values := s.AsArray()
+	n := values.Length()
+	for i := uint32(0); i < n; i++ {
+		val, err := values.GetIdx(i)
+		if err != nil {
+			return err
+		}
+		if err = callback(val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
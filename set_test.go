@@ -0,0 +1,73 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"strings"
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+	ctx := v8.NewContext()
+	defer ctx.Isolate().Dispose()
+	defer ctx.Close()
+
+	s := v8.NewSet(ctx)
+	if !s.IsSet() {
+		t.Fatal("expected IsSet to be true")
+	}
+	fatalIf(t, s.Add("a"))
+	fatalIf(t, s.Add(1))
+	fatalIf(t, s.Add("a"))
+	if n := s.Size(); n != 2 {
+		t.Errorf("unexpected Size %d", n)
+	}
+	if has, _ := s.Has(1); !has {
+		t.Error("expected Has(1)")
+	}
+	if has, _ := s.Has("b"); has {
+		t.Error("expected !Has(\"b\")")
+	}
+
+	var values []string
+	fatalIf(t, s.ForEach(func(v *v8.Value) error {
+		values = append(values, v.String())
+		return nil
+	}))
+	if str := strings.Join(values, ","); str != "a,1" {
+		t.Errorf("unexpected values: %s", str)
+	}
+	if n := s.AsArray().Length(); n != 2 {
+		t.Errorf("unexpected AsArray length %d", n)
+	}
+
+	if deleted, _ := s.Delete("a"); !deleted {
+		t.Error("expected Delete to return true")
+	}
+	fatalIf(t, ctx.Global().Set("s", s))
+	val, err := ctx.RunScript("[...s].join()", "")
+	fatalIf(t, err)
+	if val.String() != "1" {
+		t.Errorf("unexpected contents from JS: %v", val)
+	}
+	s.Clear()
+	if s.Size() != 0 {
+		t.Error("expected Clear to empty the Set")
+	}
+
+	val, err = ctx.RunScript("new Set([1, 2, 3])", "")
+	fatalIf(t, err)
+	fromJS, err := val.AsSet()
+	fatalIf(t, err)
+	if fromJS.Size() != 3 {
+		t.Errorf("unexpected Size %d", fromJS.Size())
+	}
+	if _, err := val.AsMap(); err == nil {
+		t.Error("expected error converting Set to Map")
+	}
+}
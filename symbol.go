@@ -0,0 +1,133 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+/*
+#include "v8go.h"
+static RtnValue NewSymbolGo(ContextPtr ctx, _GoString_ desc) {
+	return NewSymbol(ctx, _GoStringPtr(desc), _GoStringLen(desc)); }
+static RtnValue SymbolForGo(ContextPtr ctx, _GoString_ key) {
+	return SymbolFor(ctx, _GoStringPtr(key), _GoStringLen(key)); }
+*/
+import "C"
+
+// Symbol is a JavaScript symbol: a unique primitive value, mostly used as a property key
+// that can't collide with string keys. Use it with Object.SetKey, GetKey, etc.
+type Symbol struct {
+	*Value
+}
+
+// NewSymbol creates a new unique Symbol with the given description, which is used only for
+// debugging. An empty description creates a Symbol without one, like `Symbol()`.
+func NewSymbol(ctx *Context, description string) (*Symbol, error) {
+	var rtn C.RtnValue
+	if description == "" {
+		rtn = C.NewSymbol(ctx.ptr, nil, 0)
+	} else {
+		rtn = C.NewSymbolGo(ctx.ptr, description)
+	}
+	val, err := valueResult(ctx, rtn)
+	if err != nil {
+		return nil, err
+	}
+	return &Symbol{val}, nil
+}
+
+// SymbolFor returns the Symbol registered under the given key in the global symbol registry,
+// creating it if necessary, like `Symbol.for(key)`. The registry is shared by all Contexts of
+// an Isolate.
+func SymbolFor(ctx *Context, key string) (*Symbol, error) {
+	val, err := valueResult(ctx, C.SymbolForGo(ctx.ptr, key))
+	if err != nil {
+		return nil, err
+	}
+	return &Symbol{val}, nil
+}
+
+// Description returns the Symbol's description, or "" if it has none.
+func (s *Symbol) Description() string {
+	desc := &Value{C.SymbolDescription(s.valuePtr()), s.ctx}
+	if desc.IsUndefined() {
+		return ""
+	}
+	return desc.String()
+}
+
+type wellKnownSymbol int
+
+// This MUST be kept in sync with `WellKnownSymbol` in v8go.h!
+const (
+	asyncIteratorSymbol wellKnownSymbol = iota
+	hasInstanceSymbol
+	isConcatSpreadableSymbol
+	iteratorSymbol
+	matchSymbol
+	replaceSymbol
+	searchSymbol
+	splitSymbol
+	toPrimitiveSymbol
+	toStringTagSymbol
+	unscopablesSymbol
+)
+
+func getWellKnownSymbol(iso *Isolate, which wellKnownSymbol) *Symbol {
+	ctx := iso.internalContext
+	return &Symbol{&Value{C.GetWellKnownSymbol(ctx.ptr, C.int(which)), ctx}}
+}
+
+// SymbolAsyncIterator returns `Symbol.asyncIterator`.
+func SymbolAsyncIterator(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, asyncIteratorSymbol)
+}
+
+// SymbolHasInstance returns `Symbol.hasInstance`.
+func SymbolHasInstance(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, hasInstanceSymbol)
+}
+
+// SymbolIsConcatSpreadable returns `Symbol.isConcatSpreadable`.
+func SymbolIsConcatSpreadable(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, isConcatSpreadableSymbol)
+}
+
+// SymbolIterator returns `Symbol.iterator`.
+func SymbolIterator(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, iteratorSymbol)
+}
+
+// SymbolMatch returns `Symbol.match`.
+func SymbolMatch(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, matchSymbol)
+}
+
+// SymbolReplace returns `Symbol.replace`.
+func SymbolReplace(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, replaceSymbol)
+}
+
+// SymbolSearch returns `Symbol.search`.
+func SymbolSearch(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, searchSymbol)
+}
+
+// SymbolSplit returns `Symbol.split`.
+func SymbolSplit(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, splitSymbol)
+}
+
+// SymbolToPrimitive returns `Symbol.toPrimitive`.
+func SymbolToPrimitive(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, toPrimitiveSymbol)
+}
+
+// SymbolToStringTag returns `Symbol.toStringTag`.
+func SymbolToStringTag(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, toStringTagSymbol)
+}
+
+// SymbolUnscopables returns `Symbol.unscopables`.
+func SymbolUnscopables(iso *Isolate) *Symbol {
+	return getWellKnownSymbol(iso, unscopablesSymbol)
+}
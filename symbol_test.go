@@ -0,0 +1,117 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"testing"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+func TestSymbol(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	sym1, err := v8.NewSymbol(ctx, "secret")
+	fatalIf(t, err)
+	sym2, err := v8.NewSymbol(ctx, "secret")
+	fatalIf(t, err)
+	if !sym1.IsSymbol() {
+		t.Fatal("expected IsSymbol to be true")
+	}
+	if sym1.SameValue(sym2.Value) {
+		t.Error("expected NewSymbol to create unique symbols")
+	}
+	if d := sym1.Description(); d != "secret" {
+		t.Errorf("unexpected Description %q", d)
+	}
+	anon, err := v8.NewSymbol(ctx, "")
+	fatalIf(t, err)
+	if d := anon.SymbolDescription(); d != "" {
+		t.Errorf("unexpected Description %q", d)
+	}
+
+	// Symbol keys don't collide with string keys:
+	obj := ctx.NewObject()
+	fatalIf(t, obj.SetKey(sym1.Value, "hidden"))
+	fatalIf(t, obj.Set("secret", "visible"))
+	val, err := obj.GetKey(sym1.Value)
+	fatalIf(t, err)
+	if val.String() != "hidden" {
+		t.Errorf("unexpected value for symbol key: %v", val)
+	}
+	if obj.HasKey(sym2.Value) {
+		t.Error("expected different symbol not to be a key")
+	}
+
+	reg, err := v8.SymbolFor(ctx, "app.key")
+	fatalIf(t, err)
+	fatalIf(t, ctx.Global().Set("reg", reg))
+	same, err := ctx.RunScript("reg === Symbol.for('app.key') && Symbol.keyFor(reg)", "")
+	fatalIf(t, err)
+	if same.String() != "app.key" {
+		t.Errorf("expected SymbolFor to use the global registry, got %v", same)
+	}
+
+	val, err = ctx.RunScript("Symbol('from js')", "")
+	fatalIf(t, err)
+	if d := val.SymbolDescription(); d != "from js" {
+		t.Errorf("unexpected SymbolDescription %q", d)
+	}
+	if _, err := val.AsSymbol(); err != nil {
+		t.Error(err)
+	}
+	if _, err := obj.Value.AsSymbol(); err == nil {
+		t.Error("expected error converting non-Symbol")
+	}
+}
+
+func TestWellKnownSymbols(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	obj := ctx.NewObject()
+	gen, err := ctx.RunScript("(function*() { yield 1; yield 2; })", "")
+	fatalIf(t, err)
+	fatalIf(t, obj.SetKey(v8.SymbolIterator(iso).Value, gen))
+	fatalIf(t, obj.SetKey(v8.SymbolToStringTag(iso).Value, "Thing"))
+	fatalIf(t, ctx.Global().Set("obj", obj))
+	val, err := ctx.RunScript("[...obj].join() + ' ' + Object.prototype.toString.call(obj)", "")
+	fatalIf(t, err)
+	if s := val.String(); s != "1,2 [object Thing]" {
+		t.Errorf("unexpected result: %s", s)
+	}
+
+	symbols := map[string]*v8.Symbol{
+		"asyncIterator":      v8.SymbolAsyncIterator(iso),
+		"hasInstance":        v8.SymbolHasInstance(iso),
+		"isConcatSpreadable": v8.SymbolIsConcatSpreadable(iso),
+		"iterator":           v8.SymbolIterator(iso),
+		"match":              v8.SymbolMatch(iso),
+		"replace":            v8.SymbolReplace(iso),
+		"search":             v8.SymbolSearch(iso),
+		"split":              v8.SymbolSplit(iso),
+		"toPrimitive":        v8.SymbolToPrimitive(iso),
+		"toStringTag":        v8.SymbolToStringTag(iso),
+		"unscopables":        v8.SymbolUnscopables(iso),
+	}
+	for name, sym := range symbols {
+		fatalIf(t, ctx.Global().Set("sym", sym))
+		val, err := ctx.RunScript("sym === Symbol."+name, "")
+		fatalIf(t, err)
+		if !val.Boolean() {
+			t.Errorf("wrong symbol for Symbol.%s", name)
+		}
+		if d := sym.Description(); d != "Symbol."+name {
+			t.Errorf("unexpected description %q for Symbol.%s", d, name)
+		}
+	}
+}
@@ -81,8 +81,11 @@ int ObjectTemplateInternalFieldCount(TemplatePtr ptr) {
 /********** FunctionTemplate **********/
 
 namespace v8go {
-  // declared in v8go.hh
-  void FunctionTemplateCallback(const FunctionCallbackInfo<Value>& info) {
+  using GoCallback = ValuePtr (*)(uintptr_t ctxHandle, GoInt ref, ValueRef* thisAndArgs,
+                                 GoInt argsCount);
+
+  // Calls a Go callback with `this` and the arguments, and returns its result to JS.
+  static void callGoCallback(const FunctionCallbackInfo<Value>& info, GoCallback goCallback) {
     Isolate* iso = info.GetIsolate();
     WithIsolate _withiso(iso);
 
@@ -103,13 +106,23 @@ namespace v8go {
       thisAndArgs[1+i] = ctx->addValue(info[i]);
     }
 
-    ValuePtr val = goFunctionCallback(ctx->goRef, callback_ref, thisAndArgs, args_count);
+    ValuePtr val = goCallback(ctx->goRef, callback_ref, thisAndArgs, args_count);
     if (val.ctx != nullptr) {
       info.GetReturnValue().Set(Deref(val));
     } else {
       info.GetReturnValue().SetUndefined();
     }
   }
+
+  // declared in v8go.hh
+  void FunctionTemplateCallback(const FunctionCallbackInfo<Value>& info) {
+    callGoCallback(info, &goFunctionCallback);
+  }
+
+  // declared in v8go.hh
+  void NewFunctionCallback(const FunctionCallbackInfo<Value>& info) {
+    callGoCallback(info, &goNewFunctionCallback);
+  }
 }
 
 TemplatePtr NewFunctionTemplate(IsolatePtr iso, int callback_ref) {
@@ -128,6 +141,19 @@ TemplatePtr NewFunctionTemplate(IsolatePtr iso, int callback_ref) {
   return ot;
 }
 
+RtnValue NewFunction(ContextPtr ctx, ValuePtr name, int callback_id, int length) {
+  WithContext _with(ctx);
+  Local<Integer> cbData = Integer::New(_with.iso(), callback_id);
+  MaybeLocal<Function> maybeFn = Function::New(_with.local_ctx, NewFunctionCallback, cbData,
+                                               length);
+  Local<Function> fn;
+  if (maybeFn.ToLocal(&fn)) {
+    fn->SetName(Deref(name).As<String>());
+    ctx->addGoRef(fn, FunctionCallback_ref, callback_id);
+  }
+  return _with.returnValue(maybeFn);
+}
+
 RtnValue FunctionTemplateGetFunction(TemplatePtr ptr, ContextPtr ctx) {
   WithContext _with(ctx);
   Local<Template> tmpl(ptr->ptr.Get(_with.iso()));
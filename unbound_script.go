@@ -7,11 +7,69 @@ package v8go
 // #include <stdlib.h>
 // #include "v8go.h"
 import "C"
-import "unsafe"
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
 
+// UnboundScript is a compiled script that isn't bound to a Context, so it can be run in any
+// Context of its Isolate. Its memory in the Isolate is freed by Dispose, or after it's been
+// garbage-collected.
 type UnboundScript struct {
-	ptr C.UnboundScriptPtr
-	iso *Isolate
+	ptr    C.UnboundScriptPtr
+	iso    *Isolate
+	source string
+
+	cacheKey       string // Key in the Isolate's CodeCache, if any
+	cacheNeedsSave bool   // True if a code cache should be saved after the script runs
+}
+
+func newUnboundScript(iso *Isolate, ptr C.UnboundScriptPtr, source string) *UnboundScript {
+	u := &UnboundScript{ptr: ptr, iso: iso, source: source}
+	atomic.AddInt32(&iso.liveScripts, 1)
+	runtime.SetFinalizer(u, (*UnboundScript).finalizer)
+	return u
+}
+
+// Dispose frees the script's memory in the Isolate. Afterwards Run returns an error and the
+// other methods, except Source, return zero values. Calling Dispose again does nothing.
+// It must be called on the Isolate's goroutine.
+func (u *UnboundScript) Dispose() {
+	if u.ptr == nil {
+		return
+	}
+	if u.iso.ptr != nil {
+		C.UnboundScriptFree(u.iso.ptr, u.ptr)
+	}
+	atomic.AddInt32(&u.iso.liveScripts, -1)
+	u.ptr = nil
+	runtime.SetFinalizer(u, nil)
+}
+
+func (u *UnboundScript) finalizer() {
+	// Freeing the script isn't thread-safe to do from this finalizer goroutine, so queue it
+	// to be freed on the Isolate's goroutine by freeFinalizedScripts.
+	u.iso.finalizedMutex.Lock()
+	u.iso.finalizedScripts = append(u.iso.finalizedScripts, u.ptr)
+	u.iso.finalizedMutex.Unlock()
+	u.ptr = nil
+}
+
+// Frees the UnboundScripts queued by their finalizers.
+func (i *Isolate) freeFinalizedScripts() {
+	i.finalizedMutex.Lock()
+	scripts := i.finalizedScripts
+	i.finalizedScripts = nil
+	i.finalizedMutex.Unlock()
+	if i.ptr == nil {
+		return
+	}
+	for _, ptr := range scripts {
+		C.UnboundScriptFree(i.ptr, ptr)
+	}
+	atomic.AddInt32(&i.liveScripts, -int32(len(scripts)))
 }
 
 // Run will bind the unbound script to the provided context and run it.
@@ -22,13 +80,28 @@ func (u *UnboundScript) Run(ctx *Context) (*Value, error) {
 	if ctx.Isolate() != u.iso {
 		panic("attempted to run unbound script in a context that belongs to a different isolate")
 	}
+	if u.ptr == nil {
+		return nil, errors.New("v8go: UnboundScript has been disposed")
+	}
+	u.iso.freeFinalizedScripts()
 	rtn := C.UnboundScriptRun(ctx.ptr, u.ptr)
+	runtime.KeepAlive(u)
+	if u.cacheNeedsSave && rtn.error.msg == nil {
+		// Creating the cache after running includes the functions that were compiled lazily.
+		// If the script threw, it may not have compiled much, so wait for a successful run:
+		u.cacheNeedsSave = false
+		u.iso.codeCache.save(u.cacheKey, u.CreateCodeCache())
+	}
 	return valueResult(ctx, rtn)
 }
 
-// Create a code cache from the unbound script.
+// Create a code cache from the unbound script. Returns nil if the script has been disposed.
 func (u *UnboundScript) CreateCodeCache() *CompilerCachedData {
+	if u.ptr == nil {
+		return nil
+	}
 	rtn := C.UnboundScriptCreateCodeCache(u.iso.ptr, u.ptr)
+	runtime.KeepAlive(u)
 
 	cachedData := &CompilerCachedData{
 		Bytes:    []byte(C.GoBytes(unsafe.Pointer(rtn.data), rtn.length)),
@@ -37,3 +110,71 @@ func (u *UnboundScript) CreateCodeCache() *CompilerCachedData {
 	C.ScriptCompilerCachedDataDelete(rtn)
 	return cachedData
 }
+
+// GetId returns the script's ID, which is the `scriptId` in CPU profile nodes and debugger
+// events.
+func (u *UnboundScript) GetId() int {
+	if u.ptr == nil {
+		return 0
+	}
+	id := C.UnboundScriptGetId(u.iso.ptr, u.ptr)
+	runtime.KeepAlive(u)
+	return int(id)
+}
+
+// GetScriptName returns the script's resource name, as given when it was compiled.
+func (u *UnboundScript) GetScriptName() string {
+	if u.ptr == nil {
+		return ""
+	}
+	name := C.UnboundScriptGetScriptName(u.iso.ptr, u.ptr)
+	runtime.KeepAlive(u)
+	return rtnStringToGo(name)
+}
+
+// GetSourceURL returns the URL given by a `//# sourceURL=` comment in the script, or "".
+func (u *UnboundScript) GetSourceURL() string {
+	if u.ptr == nil {
+		return ""
+	}
+	url := C.UnboundScriptGetSourceURL(u.iso.ptr, u.ptr)
+	runtime.KeepAlive(u)
+	return rtnStringToGo(url)
+}
+
+// GetSourceMappingURL returns the URL given by a `//# sourceMappingURL=` comment in the
+// script, or "".
+func (u *UnboundScript) GetSourceMappingURL() string {
+	if u.ptr == nil {
+		return ""
+	}
+	url := C.UnboundScriptGetSourceMappingURL(u.iso.ptr, u.ptr)
+	runtime.KeepAlive(u)
+	return rtnStringToGo(url)
+}
+
+// GetLineNumber returns the zero-based line number of a character offset in the script's
+// source, or -1 if it's out of range.
+func (u *UnboundScript) GetLineNumber(codePos int) int {
+	if u.ptr == nil {
+		return -1
+	}
+	line := C.UnboundScriptGetLineNumber(u.iso.ptr, u.ptr, C.int(codePos))
+	runtime.KeepAlive(u)
+	return int(line)
+}
+
+// Source returns the script's source code, if it was compiled with CompileOptions.RetainSource;
+// otherwise "".
+func (u *UnboundScript) Source() string {
+	return u.source
+}
+
+// Converts a malloc-ed RtnString to a Go string and frees it.
+func rtnStringToGo(s C.RtnString) string {
+	if s.data == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(s.data))
+	return C.GoStringN(s.data, C.int(s.length))
+}
@@ -5,7 +5,10 @@
 package v8go_test
 
 import (
+	"fmt"
+	"runtime"
 	"testing"
+	"time"
 
 	v8 "github.com/couchbasedeps/v8go"
 )
@@ -45,3 +48,106 @@ func TestUnboundScriptRun_OnlyInTheSameIsolate(t *testing.T) {
 		t.Error("expected panic running unbound script in a context belonging to a different isolate")
 	}
 }
+
+func TestUnboundScriptIntrospection(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	source := "function foo() {}\nfunction bar() {}\n//# sourceURL=bundle.js\n//# sourceMappingURL=bundle.js.map\n"
+	us, err := iso.CompileUnboundScript(source, "app.js", v8.CompileOptions{RetainSource: true})
+	fatalIf(t, err)
+	if us.GetScriptName() != "app.js" {
+		t.Errorf("unexpected script name %q", us.GetScriptName())
+	}
+	if us.GetSourceURL() != "bundle.js" || us.GetSourceMappingURL() != "bundle.js.map" {
+		t.Errorf("unexpected source URL %q, mapping URL %q", us.GetSourceURL(), us.GetSourceMappingURL())
+	}
+	if us.Source() != source {
+		t.Errorf("unexpected source %q", us.Source())
+	}
+	other, err := iso.CompileUnboundScript(source, "app.js", v8.CompileOptions{})
+	fatalIf(t, err)
+	if other.Source() != "" {
+		t.Errorf("expected no source without RetainSource, got %q", other.Source())
+	}
+	if line := us.GetLineNumber(len("function foo() {}\nfunction ")); line != 1 {
+		t.Errorf("expected line 1, got %d", line)
+	}
+
+	_, err = us.Run(ctx)
+	fatalIf(t, err)
+	bar, err := ctx.Global().Get("bar")
+	fatalIf(t, err)
+	fn, _ := bar.AsFunction()
+	if us.GetId() == 0 || us.GetId() != fn.ScriptId() {
+		t.Errorf("expected script ID %d to match function's %d", us.GetId(), fn.ScriptId())
+	}
+}
+
+func TestUnboundScriptDispose(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	us, err := iso.CompileUnboundScript("1 + 2", "script.js", v8.CompileOptions{RetainSource: true})
+	fatalIf(t, err)
+	val, err := us.Run(ctx)
+	fatalIf(t, err)
+	if val.Int32() != 3 {
+		t.Errorf("expected 3, got %v", val)
+	}
+
+	us.Dispose()
+	us.Dispose()
+	if _, err := us.Run(ctx); err == nil {
+		t.Error("expected an error running a disposed script")
+	}
+	if us.CreateCodeCache() != nil {
+		t.Error("expected no code cache from a disposed script")
+	}
+	if us.GetId() != 0 || us.GetScriptName() != "" || us.GetLineNumber(0) != -1 {
+		t.Error("expected zero values from a disposed script")
+	}
+	if us.Source() != "1 + 2" {
+		t.Errorf("unexpected source %q", us.Source())
+	}
+}
+
+func TestUnboundScriptFinalizer(t *testing.T) {
+	t.Parallel()
+
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	us, err := iso.CompileUnboundScript("'still works'", "script.js", v8.CompileOptions{})
+	fatalIf(t, err)
+	for i := 0; i < 100; i++ {
+		_, err := iso.CompileUnboundScript(fmt.Sprintf("%d", i), "script.js", v8.CompileOptions{})
+		fatalIf(t, err)
+	}
+	if n := iso.UnboundScriptCount(); n != 101 {
+		t.Fatalf("expected 101 live scripts, got %d", n)
+	}
+
+	// Running a script frees the scripts queued by their finalizers, which run asynchronously:
+	for i := 0; i < 100 && iso.UnboundScriptCount() > 1; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+		val, err := us.Run(ctx)
+		fatalIf(t, err)
+		if val.String() != "still works" {
+			t.Fatalf("unexpected result %q", val.String())
+		}
+	}
+	if n := iso.UnboundScriptCount(); n != 1 {
+		t.Errorf("expected the unreachable scripts to be freed, %d scripts remain", n)
+	}
+}
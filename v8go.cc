@@ -96,6 +96,39 @@ namespace v8go {
     return rtn;
   }
 
+  ScriptOrigin NewScriptOrigin(Isolate* iso, const ScriptOriginParams& params,
+                               Local<String> defaultName) {
+    Local<String> name = defaultName;
+    if (params.resourceName) {
+      name = String::NewFromUtf8(iso, params.resourceName, NewStringType::kNormal,
+                                 params.resourceNameLen).ToLocalChecked();
+    }
+    Local<Value> sourceMapUrl;
+    if (params.sourceMapUrl) {
+      sourceMapUrl = String::NewFromUtf8(iso, params.sourceMapUrl, NewStringType::kNormal,
+                                         params.sourceMapUrlLen).ToLocalChecked();
+    }
+    Local<PrimitiveArray> hostDefinedOptions;
+    if (params.hostDefinedOptionsCount > 0) {
+      hostDefinedOptions = PrimitiveArray::New(iso, params.hostDefinedOptionsCount);
+      for (int i = 0; i < params.hostDefinedOptionsCount; i++) {
+        // (Local<Primitive> has no Cast, so cast to the specific primitive type)
+        Local<Value> val = Deref(params.hostDefinedOptions[i]);
+        Local<Primitive> item;
+        if (val->IsString())        item = val.As<String>();
+        else if (val->IsNumber())   item = val.As<Number>();
+        else if (val->IsBoolean())  item = val.As<Boolean>();
+        else if (val->IsBigInt())   item = val.As<BigInt>();
+        else if (val->IsSymbol())   item = val.As<Symbol>();
+        else if (val->IsNull())     item = Null(iso);
+        else                        item = Undefined(iso);
+        hostDefinedOptions->Set(iso, i, item);
+      }
+    }
+    return ScriptOrigin(iso, name, params.lineOffset, params.columnOffset, params.isShared,
+                        -1, sourceMapUrl, params.isOpaque, false, false, hostDefinedOptions);
+  }
+
 }
 
 
@@ -24,10 +24,15 @@ typedef const v8CpuProfileNode* CpuProfileNodePtr;
 typedef struct v8ScriptCompilerCachedData v8ScriptCompilerCachedData;
 typedef const v8ScriptCompilerCachedData* ScriptCompilerCachedDataPtr;
 
+typedef struct v8Unlocker v8Unlocker;
+typedef v8Unlocker* UnlockerPtr;
+
 typedef struct WithIsolate* WithIsolatePtr;
 typedef struct V8GoContext* ContextPtr;
 typedef struct V8GoTemplate* TemplatePtr;
 typedef struct V8GoUnboundScript* UnboundScriptPtr;
+typedef struct V8GoSerializedValue* SerializedValuePtr;
+typedef struct V8GoScriptStreamer* ScriptStreamerPtr;
 
 #endif
 
@@ -75,11 +80,32 @@ typedef struct {
   int rejected;
 } ScriptCompilerCachedData;
 
+// Optional parts of a ScriptOrigin; a NULL resourceName means the default name is used.
+typedef struct {
+  const char* resourceName;
+  int resourceNameLen;
+  const char* sourceMapUrl;
+  int sourceMapUrlLen;
+  int lineOffset;
+  int columnOffset;
+  Bool isShared;
+  Bool isOpaque;
+  int hostDefinedOptionsCount;
+  ValuePtr* hostDefinedOptions;
+} ScriptOriginParams;
+
 typedef struct {
   ScriptCompilerCachedData cachedData;
   int compileOption;
+  ScriptOriginParams origin;
 } CompileOptions;
 
+typedef struct {
+  ValueRef value;
+  int cachedDataRejected;
+  RtnError error;
+} RtnCompiledFunction;
+
 typedef struct {
   CpuProfilerPtr ptr;
   IsolatePtr iso;
@@ -118,6 +144,27 @@ typedef struct {
   RtnError error;
 } RtnString;
 
+typedef struct {
+  SerializedValuePtr ptr;
+  RtnError error;
+} RtnSerializedValue;
+
+typedef struct {
+  ValueRef resourceName;
+  ValueRef sourceMapUrl;
+  int lineOffset;
+  int columnOffset;
+  Bool isShared;
+  Bool isOpaque;
+  Bool isModule;
+} ScriptOriginInfo;
+
+typedef struct {
+  void* data;
+  size_t length;
+  char* error;
+} RtnHostObject;
+
 typedef struct {
   size_t total_heap_size;
   size_t total_heap_size_executable;
@@ -130,6 +177,8 @@ typedef struct {
   size_t peak_malloced_memory;
   size_t number_of_native_contexts;
   size_t number_of_detached_contexts;
+  size_t array_buffer_memory;
+  size_t array_buffer_memory_limit;
 } IsolateHStatistics;
 
 typedef struct {
@@ -152,6 +201,61 @@ typedef enum {    // This MUST be kept in sync with `ValueType` in value.go!
   Object_val,
 } ValueType;
 
+typedef enum {    // This MUST be kept in sync with `TypedArrayKind` in array_buffer.go!
+  Uint8Array_kind = 0,
+  Uint8ClampedArray_kind,
+  Int8Array_kind,
+  Uint16Array_kind,
+  Int16Array_kind,
+  Uint32Array_kind,
+  Int32Array_kind,
+  Float32Array_kind,
+  Float64Array_kind,
+  BigInt64Array_kind,
+  BigUint64Array_kind,
+} TypedArrayKind;
+
+typedef enum {    // This MUST be kept in sync with `wellKnownSymbol` in symbol.go!
+  AsyncIterator_sym = 0,
+  HasInstance_sym,
+  IsConcatSpreadable_sym,
+  Iterator_sym,
+  Match_sym,
+  Replace_sym,
+  Search_sym,
+  Split_sym,
+  ToPrimitive_sym,
+  ToStringTag_sym,
+  Unscopables_sym,
+} WellKnownSymbol;
+
+typedef enum {    // This MUST be kept in sync with `objectIntrinsic` in object.go!
+  PreventExtensions_fn = 0,
+  IsExtensible_fn,
+  IsSealed_fn,
+  IsFrozen_fn,
+  kNumObjectIntrinsics
+} ObjectIntrinsic;
+
+typedef enum {    // This MUST be kept in sync with `goRefKind` in context.go!
+  FunctionCallback_ref = 0,
+  ProxyHandler_ref,
+  Iterator_ref,
+} GoRefKind;
+
+typedef enum {    // This MUST be kept in sync with `ErrorKind` in errors.go!
+  Error_kind = 0,
+  TypeError_kind,
+  RangeError_kind,
+  SyntaxError_kind,
+  ReferenceError_kind,
+} ErrorKind;
+
+typedef struct {
+  void* data;
+  size_t length;
+} ArrayBufferContents;
+
 typedef struct {
   IsolatePtr isolate;
   ContextPtr internalContext;
@@ -159,14 +263,21 @@ typedef struct {
 } NewIsolateResult;
 
 extern void Init();
-extern NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit);
+extern NewIsolateResult NewIsolate(size_t initialHeap, size_t heapLimit,
+                                   size_t arrayBufferLimit);
 extern void IsolatePerformMicrotaskCheckpoint(IsolatePtr ptr);
 extern void IsolateDispose(IsolatePtr ptr);
 extern WithIsolatePtr IsolateLock(IsolatePtr);
 extern void IsolateUnlock(WithIsolatePtr);
+extern int IsolateIsLockedByCurrentThread(IsolatePtr);
+extern UnlockerPtr IsolateNewUnlocker(IsolatePtr);
+extern void UnlockerFree(UnlockerPtr);
 extern void IsolateTerminateExecution(IsolatePtr ptr);
 extern int IsolateIsExecutionTerminating(IsolatePtr ptr);
+extern int IsolateTakeTerminated(IsolatePtr ptr);
+extern void IsolateLowMemoryNotification(IsolatePtr ptr);
 extern IsolateHStatistics IsolationGetHeapStatistics(IsolatePtr ptr);
+extern void IsolateSetAllowAtomicsWait(IsolatePtr ptr, Bool allow);
 
 extern ValueRef IsolateThrowException(IsolatePtr iso, ValuePtr value);
 
@@ -180,6 +291,22 @@ extern ScriptCompilerCachedData* UnboundScriptCreateCodeCache(
 extern void ScriptCompilerCachedDataDelete(
     ScriptCompilerCachedData* cached_data);
 extern RtnValue UnboundScriptRun(ContextPtr ctx_ptr, UnboundScriptPtr us_ptr);
+extern void UnboundScriptFree(IsolatePtr iso_ptr, UnboundScriptPtr us_ptr);
+extern int UnboundScriptGetId(IsolatePtr iso_ptr, UnboundScriptPtr us_ptr);
+extern RtnString UnboundScriptGetScriptName(IsolatePtr iso_ptr, UnboundScriptPtr us_ptr);
+extern RtnString UnboundScriptGetSourceURL(IsolatePtr iso_ptr, UnboundScriptPtr us_ptr);
+extern RtnString UnboundScriptGetSourceMappingURL(IsolatePtr iso_ptr, UnboundScriptPtr us_ptr);
+extern int UnboundScriptGetLineNumber(IsolatePtr iso_ptr, UnboundScriptPtr us_ptr, int code_pos);
+
+extern uint32_t CachedDataVersionTag();
+
+extern ScriptStreamerPtr NewScriptStreamer(IsolatePtr iso_ptr, uintptr_t readerHandle,
+                                           CompileOptions options);
+extern void ScriptStreamerRun(ScriptStreamerPtr ptr);
+extern RtnUnboundScript ScriptStreamerFinish(ScriptStreamerPtr ptr,
+                                             const char* origin, int originLen,
+                                             CompileOptions options);
+extern void ScriptStreamerFree(ScriptStreamerPtr ptr);
 
 extern CPUProfiler* NewCPUProfiler(IsolatePtr iso_ptr);
 extern void CPUProfilerDispose(CPUProfiler* ptr);
@@ -195,6 +322,15 @@ extern void ContextFree(ContextPtr ptr);
 extern RtnValue RunScript(ContextPtr ctx_ptr,
                           const char* source, int sourceLen,
                           const char* origin, int originLen);
+extern RtnValue RunScriptWithOrigin(ContextPtr ctx_ptr,
+                                    const char* source, int sourceLen,
+                                    ScriptOriginParams origin);
+extern RtnCompiledFunction ContextCompileFunction(ContextPtr ctx_ptr,
+                                                  const char* source, int sourceLen,
+                                                  const char* origin, int originLen,
+                                                  int paramCount, ValuePtr params[],
+                                                  int extensionCount, ValuePtr extensions[],
+                                                  CompileOptions options);
 extern RtnValue JSONParse(ContextPtr ctx_ptr, const char* str, int len);
 extern RtnString JSONStringify(ValuePtr, void *buffer, int bufferSize);
 extern ValueRef ContextGlobal(ContextPtr ctx_ptr);
@@ -216,6 +352,7 @@ extern void ObjectTemplateSetInternalFieldCount(TemplatePtr ptr,
 extern int ObjectTemplateInternalFieldCount(TemplatePtr ptr);
 
 extern TemplatePtr NewFunctionTemplate(IsolatePtr iso_ptr, int callback_ref);
+extern RtnValue NewFunction(ContextPtr ctx, ValuePtr name, int callback_id, int length);
 extern RtnValue FunctionTemplateGetFunction(TemplatePtr ptr,
                                             ContextPtr ctx_ptr);
 
@@ -232,6 +369,10 @@ extern RtnValue NewValueBigIntFromWords(ContextPtr,
                                         int sign_bit,
                                         int word_count,
                                         const uint64_t* words);
+extern RtnValue NewSymbol(ContextPtr, const char* desc, int desc_length);
+extern RtnValue SymbolFor(ContextPtr, const char* key, int key_length);
+extern ValueRef GetWellKnownSymbol(ContextPtr, int which);
+extern ValueRef SymbolDescription(ValuePtr ptr);
 extern RtnString ValueToString(ValuePtr ptr, void *buffer, int bufferSize);
 const uint32_t* ValueToArrayIndex(ValuePtr ptr);
 int ValueToBoolean(ValuePtr ptr);
@@ -312,13 +453,64 @@ extern ValuePtr ObjectGetInternalField(ValuePtr obj, int idx);
 extern int ObjectHas(ValuePtr obj, const char* key, int keyLen);
 extern int ObjectHasKey(ValuePtr obj, ValuePtr key);
 extern int ObjectHasIdx(ValuePtr obj, uint32_t idx);
+extern int ObjectHasOwnProperty(ValuePtr obj, const char* key, int keyLen);
+extern void ObjectAddGoRef(ValuePtr obj, GoRefKind kind, int id);
 extern int ObjectDelete(ValuePtr obj, const char* key, int keyLen);
 extern int ObjectDeleteKey(ValuePtr obj, ValuePtr key);
 extern int ObjectDeleteIdx(ValuePtr obj, uint32_t idx);
+extern ValueRef ObjectGetPrototype(ValuePtr obj);
+extern RtnValue ObjectSetPrototype(ValuePtr obj, ValuePtr proto);
+extern RtnValue ObjectSetIntegrityLevel(ValuePtr obj, int frozen);
+extern ValueRef ObjectGetConstructorName(ValuePtr obj);
+extern RtnValue ObjectCallIntrinsic(ValuePtr obj, int which);
+extern RtnValue ObjectGetOwnPropertyDescriptor(ValuePtr obj, ValuePtr key);
+extern RtnValue ValueInstanceOf(ValuePtr ptr, ValuePtr ctor);
 
 extern ValueRef NewArray(ContextPtr, uint32_t length);
 extern uint32_t ArrayLength(ValuePtr ptr);
 
+extern ValueRef NewMap(ContextPtr);
+extern RtnValue MapGet(ValuePtr ptr, ValuePtr key);
+extern RtnValue MapSet(ValuePtr ptr, ValuePtr key, ValuePtr val);
+extern int MapHas(ValuePtr ptr, ValuePtr key);
+extern int MapDelete(ValuePtr ptr, ValuePtr key);
+extern void MapClear(ValuePtr ptr);
+extern size_t MapSize(ValuePtr ptr);
+extern ValueRef MapAsArray(ValuePtr ptr);
+
+extern ValueRef NewSet(ContextPtr);
+extern RtnValue SetAdd(ValuePtr ptr, ValuePtr key);
+extern int SetHas(ValuePtr ptr, ValuePtr key);
+extern int SetDelete(ValuePtr ptr, ValuePtr key);
+extern void SetClear(ValuePtr ptr);
+extern size_t SetSize(ValuePtr ptr);
+extern ValueRef SetAsArray(ValuePtr ptr);
+
+extern RtnValue NewDate(ContextPtr, double time);
+extern double DateValueOf(ValuePtr ptr);
+
+extern RtnValue NewError(ContextPtr, int kind, ValuePtr message, ValuePtr cause);
+
+extern RtnValue NewProxy(ValuePtr target, ValuePtr handler);
+extern ValueRef ProxyGetTarget(ValuePtr ptr);
+extern ValueRef ProxyGetHandler(ValuePtr ptr);
+extern int ProxyIsRevoked(ValuePtr ptr);
+extern void ProxyRevoke(ValuePtr ptr);
+
+extern RtnValue NewArrayBuffer(ContextPtr, const void* data, size_t length);
+extern ArrayBufferContents ArrayBufferGetContents(ValuePtr ptr);
+extern int ArrayBufferIsDetachable(ValuePtr ptr);
+extern int ArrayBufferDetach(ValuePtr ptr);
+extern RtnValue ArrayBufferTransfer(ValuePtr ptr, ContextPtr ctx);
+extern RtnValue NewSharedArrayBuffer(ContextPtr, size_t length);
+extern RtnValue SharedArrayBufferShareWith(ValuePtr ptr, ContextPtr ctx);
+extern RtnValue NewTypedArray(ValuePtr buffer, int /*TypedArrayKind*/ kind,
+                              size_t byteOffset, size_t length);
+extern ValueRef ArrayBufferViewBuffer(ValuePtr ptr);
+extern size_t ArrayBufferViewByteOffset(ValuePtr ptr);
+extern size_t ArrayBufferViewByteLength(ValuePtr ptr);
+extern size_t TypedArrayLength(ValuePtr ptr);
+
 extern RtnValue NewPromiseResolver(ContextPtr ctx_ptr);
 extern ValueRef PromiseResolverGetPromise(ValuePtr ptr);
 int PromiseResolverResolve(ValuePtr ptr, ValuePtr val_ptr);
@@ -329,12 +521,38 @@ RtnValue PromiseThen2(ValuePtr ptr, int on_fulfilled_ref, int on_rejected_ref);
 RtnValue PromiseCatch(ValuePtr ptr, int callback_ref);
 extern ValueRef PromiseResult(ValuePtr ptr);
 
+extern RtnSerializedValue SerializeValue(ValuePtr ptr,
+                                         int transferCount,
+                                         ValuePtr transfer[],
+                                         Bool takeBuffers,
+                                         uintptr_t hostObjectHandle);
+extern const void* SerializedValueData(SerializedValuePtr ser, size_t* length);
+extern RtnValue DeserializeValue(ContextPtr ctx, SerializedValuePtr ser);
+extern RtnValue DeserializeBytes(ContextPtr ctx,
+                                 const void* data,
+                                 size_t length,
+                                 int transferCount,
+                                 ValuePtr transfer[],
+                                 uintptr_t hostObjectHandle);
+extern void SerializedValueFree(SerializedValuePtr ser);
+
 extern RtnValue FunctionCall(ValuePtr ptr,
                              ValuePtr recv,
                              int argc,
                              ValuePtr argv[]);
 RtnValue FunctionNewInstance(ValuePtr ptr, int argc, ValuePtr args[]);
 ValueRef FunctionSourceMapUrl(ValuePtr ptr);
+extern ScriptCompilerCachedData* FunctionCreateCodeCache(ValuePtr ptr);
+extern ValueRef FunctionGetName(ValuePtr ptr);
+extern void FunctionSetName(ValuePtr ptr, ValuePtr name);
+extern ValueRef FunctionGetInferredName(ValuePtr ptr);
+extern ValueRef FunctionGetDebugName(ValuePtr ptr);
+extern ScriptOriginInfo FunctionGetScriptOrigin(ValuePtr ptr);
+extern int FunctionScriptId(ValuePtr ptr);
+extern int FunctionGetScriptLineNumber(ValuePtr ptr);
+extern int FunctionGetScriptColumnNumber(ValuePtr ptr);
+extern ValueRef FunctionGetBoundFunction(ValuePtr ptr);
+extern int FunctionIsNative(ValuePtr ptr);
 
 const char* V8Version();
 extern void SetV8Flags(const char* flags);
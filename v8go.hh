@@ -9,13 +9,15 @@
 #include "v8.h"
 #include "v8-profiler.h"
 
+#include <atomic>
 #include <cstdio>
 #include <cstdlib>
 #include <cstring>
-#include <deque>
 #include <iostream>
+#include <memory>
 #include <sstream>
 #include <string>
+#include <unordered_set>
 #include <vector>
 
 
@@ -26,17 +28,22 @@ typedef v8::CpuProfiler* CpuProfilerPtr;
 typedef v8::CpuProfile* CpuProfilePtr;
 typedef const v8::CpuProfileNode* CpuProfileNodePtr;
 typedef v8::ScriptCompiler::CachedData* ScriptCompilerCachedDataPtr;
+typedef v8::Unlocker* UnlockerPtr;
 
 namespace v8go {
   struct WithIsolate;
   struct V8GoContext;
   struct V8GoTemplate;
   struct V8GoUnboundScript;
+  struct V8GoSerializedValue;
+  struct V8GoScriptStreamer;
 }
 typedef struct v8go::WithIsolate* WithIsolatePtr;
 typedef struct v8go::V8GoContext* ContextPtr;
 typedef struct v8go::V8GoTemplate* TemplatePtr;
 typedef struct v8go::V8GoUnboundScript* UnboundScriptPtr;
+typedef struct v8go::V8GoSerializedValue* SerializedValuePtr;
+typedef struct v8go::V8GoScriptStreamer* ScriptStreamerPtr;
 
 
 #include "v8go.h"
@@ -56,20 +63,22 @@ namespace v8go {
 
   RtnError ExceptionError(TryCatch&, Isolate*, Local<Context>);
 
+  // Creates a ScriptOrigin from the parameters, using `defaultName` if they have no name.
+  ScriptOrigin NewScriptOrigin(Isolate*, const ScriptOriginParams&, Local<String> defaultName);
+
   void FunctionTemplateCallback(const FunctionCallbackInfo<Value>& info);
+  void NewFunctionCallback(const FunctionCallbackInfo<Value>& info);
 
 
   /********** Internal Types **********/
 
+  // Created by V8GoContext::newUnboundScript() and freed by freeUnboundScript().
   struct V8GoUnboundScript {
-    Persistent<UnboundScript, CopyablePersistentTraits<UnboundScript>> const ptr;
+    Global<UnboundScript> ptr;
 
     V8GoUnboundScript(Isolate *iso, Local<UnboundScript> script)
     :ptr(iso, script)
     { }
-
-    // Prevents `new V8GoUnboundScript()` -- call m_ctx::newUnboundScript() instead.
-    static void* operator new(size_t) = delete;
   };
 
 
@@ -89,6 +98,20 @@ namespace v8go {
     bool popValueScope(uint32_t scopeID);
 
     V8GoUnboundScript* newUnboundScript(Local<UnboundScript>);
+    void freeUnboundScript(V8GoUnboundScript*);
+
+    // Arranges for a Go object tied to a JavaScript object, such as the callback of a Function
+    // created by NewFunction, to be released when the JavaScript object is garbage-collected,
+    // or when this context is freed.
+    void addGoRef(Local<Object>, GoRefKind, int id);
+
+    // Saves the static methods of `Object` used by ObjectCallIntrinsic, before any script can
+    // replace them. (The isolate's internal context doesn't need them.)
+    void captureIntrinsics();
+
+    // Returns one of the static methods of `Object`, as it was when the context was created,
+    // so scripts can't replace it. Empty if the context's `Object` didn't have it.
+    Local<Function> objectIntrinsic(ObjectIntrinsic which) {return _intrinsics[which].Get(iso);}
 
     Isolate* const iso;
     uintptr_t goRef;      // a runtime.cgo.Handle pointing to the Go Context
@@ -100,13 +123,50 @@ namespace v8go {
     std::vector<PersistentValue> _values;
     std::vector<ValueRef> _savedScopes;
     ValueScope _latestScope = 1, _curScope = 1;
-    std::deque<V8GoUnboundScript> _unboundScripts; // (deque does not invalidate refs when it grows)
+    std::unordered_set<V8GoUnboundScript*> _unboundScripts;
+    Global<Function> _intrinsics[kNumObjectIntrinsics];
+
+    struct GoRef {
+      V8GoContext* ctx;
+      GoRefKind kind;
+      int id;
+      Global<Object> obj;
+    };
+    std::unordered_set<GoRef*> _goRefs;
+
+    static void objectCollected(const WeakCallbackInfo<GoRef>&);
+    static void releaseGoRef(GoRef*);
   #ifdef CTX_LOG_VALUES
     size_t _nValues = 0, _maxValues = 0;
   #endif
   };
 
 
+  // Per-isolate ArrayBuffer allocator that tracks how much memory it has allocated, and fails
+  // allocations that would exceed its limit (which makes JS throw a RangeError.)
+  class V8GoAllocator : public ArrayBuffer::Allocator,
+                        public std::enable_shared_from_this<V8GoAllocator> {
+  public:
+    explicit V8GoAllocator(size_t limit);
+
+    static V8GoAllocator* forIsolate(Isolate*);
+
+    size_t used() const     {return _used;}
+    size_t limit() const    {return _limit;}
+
+    void* Allocate(size_t length) override;
+    void* AllocateUninitialized(size_t length) override;
+    void Free(void* data, size_t length) override;
+
+  private:
+    bool reserve(size_t length);
+
+    std::unique_ptr<ArrayBuffer::Allocator> _base;
+    std::atomic<size_t> _used {0};
+    size_t const _limit;
+  };
+
+
   struct V8GoTemplate {
     Isolate* iso;
     Persistent<Template> ptr;
@@ -46,6 +46,55 @@ RtnValue NewValueBigIntFromWords(ContextPtr ctx,
 }
 
 
+/********** Symbol **********/
+
+RtnValue NewSymbol(ContextPtr ctx, const char* desc, int desc_length) {
+  WithContext _with(ctx);
+  Local<String> description;
+  if (desc && !String::NewFromUtf8(_with.iso(), desc, NewStringType::kNormal, desc_length)
+                  .ToLocal(&description)) {
+    return _with.returnValue(MaybeLocal<Symbol>());
+  }
+  return _with.returnValue(MaybeLocal<Symbol>(Symbol::New(_with.iso(), description)));
+}
+
+RtnValue SymbolFor(ContextPtr ctx, const char* key, int key_length) {
+  WithContext _with(ctx);
+  Local<String> keyStr;
+  if (!String::NewFromUtf8(_with.iso(), key, NewStringType::kNormal, key_length)
+          .ToLocal(&keyStr)) {
+    return _with.returnValue(MaybeLocal<Symbol>());
+  }
+  return _with.returnValue(MaybeLocal<Symbol>(Symbol::For(_with.iso(), keyStr)));
+}
+
+ValueRef GetWellKnownSymbol(ContextPtr ctx, int which) {
+  WithIsolate _withiso(ctx->iso);
+  Isolate* iso = ctx->iso;
+  Local<Symbol> sym;
+  switch (which) {
+    case AsyncIterator_sym:       sym = Symbol::GetAsyncIterator(iso); break;
+    case HasInstance_sym:         sym = Symbol::GetHasInstance(iso); break;
+    case IsConcatSpreadable_sym:  sym = Symbol::GetIsConcatSpreadable(iso); break;
+    case Iterator_sym:            sym = Symbol::GetIterator(iso); break;
+    case Match_sym:               sym = Symbol::GetMatch(iso); break;
+    case Replace_sym:             sym = Symbol::GetReplace(iso); break;
+    case Search_sym:              sym = Symbol::GetSearch(iso); break;
+    case Split_sym:               sym = Symbol::GetSplit(iso); break;
+    case ToPrimitive_sym:         sym = Symbol::GetToPrimitive(iso); break;
+    case ToStringTag_sym:         sym = Symbol::GetToStringTag(iso); break;
+    case Unscopables_sym:         sym = Symbol::GetUnscopables(iso); break;
+    default:                      return ctx->addValue(Undefined(iso));
+  }
+  return ctx->addValue(sym);
+}
+
+ValueRef SymbolDescription(ValuePtr ptr) {
+  WithValue _with(ptr);
+  return _with.returnValue(_with.value.As<Symbol>()->Description(_with.iso()));
+}
+
+
 /********** Value Conversion **********/
 
 const uint32_t* ValueToArrayIndex(ValuePtr ptr) {
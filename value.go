@@ -17,7 +17,9 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"reflect"
 	"strconv"
+	"time"
 	"unsafe"
 )
 
@@ -49,6 +51,7 @@ func Null(iso *Isolate) *Value {
 
 func (val *Value) valuePtr() C.ValuePtr {
 	if ptr := val.ctx.ptr; ptr != nil {
+		val.ctx.iso.checkOwner()
 		return C.ValuePtr{ptr, val.ref}
 	} else {
 		panic("Attempt to use a v8go.Value after its Context was closed")
@@ -69,12 +72,17 @@ func NewValue(iso *Isolate, val interface{}) (*Value, error) {
 // it becomes invalid and must not be used after this Context or its Isolate is closed.
 //
 // Go types recognized are: bool, int, uint, int32, uint32, int64, uint64, *big.Int,
-// float32, float64, json.Number, string, *v8.Value, *v8.Object.
+// float32, float64, json.Number, string, time.Time, *v8.Value, *v8.Object.
+//
+// A time.Time creates a Date, truncated to milliseconds.
 //
 // If given an integer outside the range ±2^53, or a big.Int, it will create a BigInt.
 //
+// A Go map is converted to a JavaScript object if its keys are strings, otherwise to a `Map`;
+// its keys and values are converted recursively, and must be of the above types.
+//
 // As a convenience, if passed a *v8.Value it returns the same Value,
-// and if passed a *v8.Object it returns the object's Value.
+// and if passed a *v8.Object (or any other Valuer) it returns the object's Value.
 func (c *Context) NewValue(val interface{}) (*Value, error) {
 	ctxPtr := c.ptr
 	var ref C.ValueRef
@@ -109,13 +117,24 @@ func (c *Context) NewValue(val interface{}) (*Value, error) {
 		ref, err = newValueFromBigInt(ctxPtr, v)
 	case json.Number:
 		ref, err = newValueFromJSONNumber(ctxPtr, v)
+	case time.Time:
+		date, err := NewDate(c, v)
+		if err != nil {
+			return nil, err
+		}
+		return date.Value, nil
 	case *Value:
 		return v, nil
 	case *Object:
 		return v.Value, nil
 	case *Array:
 		return v.Value, nil
+	case Valuer:
+		return v.value(), nil
 	default:
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Map {
+			return c.newValueFromMap(rv)
+		}
 		err = ErrUnsupportedValueType
 	}
 
@@ -127,6 +146,26 @@ func (c *Context) NewValue(val interface{}) (*Value, error) {
 
 var ErrUnsupportedValueType = fmt.Errorf("v8go: unsupported value type")
 
+func (c *Context) newValueFromMap(m reflect.Value) (*Value, error) {
+	iter := m.MapRange()
+	if m.Type().Key().Kind() == reflect.String {
+		obj := c.NewObject()
+		for iter.Next() {
+			if err := obj.Set(iter.Key().String(), iter.Value().Interface()); err != nil {
+				return nil, err
+			}
+		}
+		return obj.Value, nil
+	}
+	jsMap := NewMap(c)
+	for iter.Next() {
+		if err := jsMap.Set(iter.Key().Interface(), iter.Value().Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return jsMap.Value, nil
+}
+
 const kMaxFloat64SafeInt = 1<<53 - 1
 const kMinFloat64SafeInt = -kMaxFloat64SafeInt
 
@@ -263,6 +302,15 @@ func (v *Value) DetailString() string {
 	return C.GoStringN(rtn.data, rtn.length)
 }
 
+// Date returns the time of a JS `Date`, in the local time zone. If the value is not a Date,
+// or is an invalid Date, it returns the zero Time.
+func (v *Value) Date() time.Time {
+	if !v.IsDate() {
+		return time.Time{}
+	}
+	return (&Date{&Object{v}}).Time()
+}
+
 // Int32 perform the equivalent of `Number(value)` in JS and convert the result to a
 // signed 32-bit integer by performing the steps in https://tc39.es/ecma262/#sec-toint32.
 func (v *Value) Int32() int32 {
@@ -296,9 +344,8 @@ func (v *Value) Object() *Object {
 // are returned as-is, objects will return `[object Object]` and functions will
 // print their definition.
 func (v *Value) String() string {
-	// It's OK to use the Isolate's shared buffer because we already require that client code can
-	// only access an Isolate, and Values derived from it, on a single goroutine at a time.
-	buffer := v.ctx.iso.stringBuffer
+	buffer := stringBuffers.Get().(*[kIsolateStringBufferSize]byte)
+	defer stringBuffers.Put(buffer)
 	bufPtr := unsafe.Pointer(&buffer[0])
 	s := C.ValueToString(v.valuePtr(), bufPtr, C.int(len(buffer)))
 	if unsafe.Pointer(s.data) == bufPtr {
@@ -662,7 +709,98 @@ func (v *Value) AsFunction() (*Function, error) {
 	if !v.IsFunction() {
 		return nil, errors.New("v8go: value is not a Function")
 	}
-	return &Function{v}, nil
+	return &Function{Value: v}, nil
+}
+
+// SymbolDescription returns the description of a Symbol, or "" if it has none or the value
+// is not a Symbol.
+func (v *Value) SymbolDescription() string {
+	if !v.IsSymbol() {
+		return ""
+	}
+	return (&Symbol{v}).Description()
+}
+
+// InstanceOf returns the result of the JavaScript expression `value instanceof ctor`.
+// Returns an error if ctor is not a constructor, or if it throws.
+func (v *Value) InstanceOf(ctor Valuer) (bool, error) {
+	ctorVal := ctor.value()
+	if !ctorVal.IsObject() {
+		return false, errors.New("v8go: right-hand side of instanceof is not an Object")
+	}
+	return boolResult(v.ctx, C.ValueInstanceOf(v.valuePtr(), ctorVal.valuePtr()))
+}
+
+// AsProxy will cast the value to the Proxy type. If the value is not a Proxy
+// then an error is returned.
+func (v *Value) AsProxy() (*Proxy, error) {
+	if !v.IsProxy() {
+		return nil, errors.New("v8go: value is not a Proxy")
+	}
+	return &Proxy{&Object{v}}, nil
+}
+
+// AsSymbol will cast the value to the Symbol type. If the value is not a Symbol
+// then an error is returned.
+func (v *Value) AsSymbol() (*Symbol, error) {
+	if !v.IsSymbol() {
+		return nil, errors.New("v8go: value is not a Symbol")
+	}
+	return &Symbol{v}, nil
+}
+
+// AsDate will cast the value to the Date type. If the value is not a Date
+// then an error is returned.
+func (v *Value) AsDate() (*Date, error) {
+	if !v.IsDate() {
+		return nil, errors.New("v8go: value is not a Date")
+	}
+	return &Date{&Object{v}}, nil
+}
+
+// AsMap will cast the value to the Map type. If the value is not a Map
+// then an error is returned.
+func (v *Value) AsMap() (*Map, error) {
+	if !v.IsMap() {
+		return nil, errors.New("v8go: value is not a Map")
+	}
+	return &Map{&Object{v}}, nil
+}
+
+// AsSet will cast the value to the Set type. If the value is not a Set
+// then an error is returned.
+func (v *Value) AsSet() (*Set, error) {
+	if !v.IsSet() {
+		return nil, errors.New("v8go: value is not a Set")
+	}
+	return &Set{&Object{v}}, nil
+}
+
+// AsArrayBuffer will cast the value to the ArrayBuffer type. If the value is not an
+// ArrayBuffer then an error is returned.
+func (v *Value) AsArrayBuffer() (*ArrayBuffer, error) {
+	if !v.IsArrayBuffer() {
+		return nil, errors.New("v8go: value is not an ArrayBuffer")
+	}
+	return &ArrayBuffer{&Object{v}}, nil
+}
+
+// AsSharedArrayBuffer will cast the value to the SharedArrayBuffer type. If the value is not a
+// SharedArrayBuffer then an error is returned.
+func (v *Value) AsSharedArrayBuffer() (*SharedArrayBuffer, error) {
+	if !v.IsSharedArrayBuffer() {
+		return nil, errors.New("v8go: value is not a SharedArrayBuffer")
+	}
+	return &SharedArrayBuffer{&Object{v}}, nil
+}
+
+// AsTypedArray will cast the value to the TypedArray type. If the value is not a
+// typed array (e.g. a `Uint8Array`) then an error is returned.
+func (v *Value) AsTypedArray() (*TypedArray, error) {
+	if !v.IsTypedArray() {
+		return nil, errors.New("v8go: value is not a TypedArray")
+	}
+	return &TypedArray{&Object{v}}, nil
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -0,0 +1,381 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Worker runs a script in its own Isolate on its own goroutine, and exchanges messages with
+// a parent Context, much like a Web Worker.
+//
+// Messages are copied with the structured clone algorithm, so they may contain nearly any
+// JavaScript data (but not functions); ArrayBuffers can be transferred instead of copied,
+// and SharedArrayBuffers are shared between the isolates.
+//
+// In the worker, the global functions `postMessage(data [, transfer])` and `close()` are
+// available, and messages from the parent are delivered to the global `onmessage` handler.
+// In the parent, the JS object returned by Worker.Object has `postMessage` and `terminate`
+// methods, and messages from the worker are delivered to its `onmessage` handler -- but only
+// when the parent calls DispatchMessages, since the parent's Isolate isn't ours to drive.
+type Worker struct {
+	id       int32
+	parent   *Context
+	obj      *Object      // The JS object representing the worker in the parent Context
+	toWorker messageQueue // Messages from the parent, not yet delivered
+	toParent messageQueue // Messages from the worker, not yet dispatched
+
+	mutex   sync.Mutex    // Guards iso, closing, err
+	iso     *Isolate      // The worker's Isolate; nil once it's exited
+	closing bool          // Set by Terminate, or by `close()` in the worker
+	err     error         // The exception that stopped the worker, if any
+	stop    chan struct{} // Closed when the worker is told to stop
+	done    chan struct{} // Closed when the worker has exited
+}
+
+var workerSeq int32
+var workers sync.Map // Maps Worker.id -> *Worker, so the JS object can find its Worker
+
+// NewWorker starts a worker that runs the given script in a new Isolate, on a new goroutine.
+// `origin` is the script's filename, as in RunScript. The worker keeps running, handling
+// messages, until its script calls `close()`, an exception goes uncaught, or Terminate is
+// called.
+func NewWorker(parent *Context, source string, origin string) (*Worker, error) {
+	if parent == nil {
+		return nil, errors.New("v8go: parent Context is required")
+	}
+	tmpl := parent.iso.workerTemplate()
+	obj, err := tmpl.NewInstance(parent)
+	if err != nil {
+		return nil, err
+	}
+	w := &Worker{
+		id:     atomic.AddInt32(&workerSeq, 1),
+		parent: parent,
+		obj:    obj,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	w.toWorker.init()
+	w.toParent.init()
+	if err := obj.SetInternalField(0, w.id); err != nil {
+		return nil, err
+	}
+	workers.Store(w.id, w)
+
+	w.iso = NewIsolate()
+	go w.run(source, origin)
+	return w, nil
+}
+
+// Object returns the JavaScript object representing the worker in the parent Context.
+// Scripts in the parent use its `postMessage`, `terminate` and `onmessage` properties.
+func (w *Worker) Object() *Object {
+	return w.obj
+}
+
+// PostMessage sends a message to the worker, to be delivered to its `onmessage` handler.
+// The ArrayBuffers listed in `transfer` are moved to the worker, and detached in the sender.
+func (w *Worker) PostMessage(val Valuer, transfer ...*ArrayBuffer) error {
+	return w.postMessage(val.value(), transferValues(transfer))
+}
+
+// DispatchMessages delivers the messages the worker has posted to the `onmessage` handler of
+// the worker's JS object in the parent Context. It doesn't block, and must be called on the
+// parent Context's goroutine. Returns the number of messages delivered; if a handler throws,
+// the remaining messages are still delivered and the first exception is returned.
+func (w *Worker) DispatchMessages() (int, error) {
+	msgs := w.toParent.takeAll()
+	var firstErr error
+	for _, msg := range msgs {
+		if err := deliverMessage(w.parent, w.obj, msg); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return len(msgs), firstErr
+}
+
+// WaitForMessage blocks until the worker has posted a message for DispatchMessages, the
+// worker exits, or the timeout elapses. Returns true if there's a message waiting.
+func (w *Worker) WaitForMessage(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for !w.toParent.hasMessages() {
+		select {
+		case <-w.toParent.signal:
+		case <-w.done:
+			return w.toParent.hasMessages()
+		case <-timer.C:
+			return false
+		}
+	}
+	return true
+}
+
+// Terminate stops the worker as soon as possible, interrupting any JavaScript it's running.
+// Pending messages in either direction are discarded.
+func (w *Worker) Terminate() {
+	w.mutex.Lock()
+	if !w.closing {
+		w.closing = true
+		close(w.stop)
+		if w.iso != nil {
+			w.iso.TerminateExecution()
+		}
+	}
+	w.mutex.Unlock()
+	w.toParent.discard()
+}
+
+// Done returns a channel that's closed when the worker has exited.
+func (w *Worker) Done() <-chan struct{} {
+	return w.done
+}
+
+// Err returns the uncaught exception that stopped the worker, if any.
+func (w *Worker) Err() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.err
+}
+
+func (w *Worker) postMessage(val *Value, transfer []*Value) error {
+	if w.isClosing() {
+		return nil
+	}
+	msg, err := serializeValue(val, transfer)
+	if err != nil {
+		return err
+	}
+	w.toWorker.push(msg)
+	return nil
+}
+
+func (w *Worker) isClosing() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.closing
+}
+
+// The worker's goroutine.
+func (w *Worker) run(source, origin string) {
+	iso := w.iso
+	ctx := NewContext(iso, workerGlobalTemplate(iso, w))
+	defer func() {
+		w.mutex.Lock()
+		w.iso = nil
+		w.mutex.Unlock()
+		ctx.Close()
+		iso.Dispose()
+		w.toWorker.discard()
+		workers.Delete(w.id)
+		close(w.done)
+	}()
+
+	global := ctx.Global()
+	global.Set("self", global)
+	if _, err := ctx.RunScript(source, origin); err != nil {
+		w.stopWithError(err)
+		return
+	}
+	ctx.PerformMicrotaskCheckpoint()
+
+	for !w.isClosing() {
+		select {
+		case <-w.toWorker.signal:
+		case <-w.stop:
+			return
+		}
+		for _, msg := range w.toWorker.takeAll() {
+			if w.isClosing() {
+				msg.free()
+				continue
+			}
+			if err := deliverMessage(ctx, global, msg); err != nil {
+				w.stopWithError(err)
+			}
+		}
+	}
+}
+
+func (w *Worker) stopWithError(err error) {
+	w.mutex.Lock()
+	if w.err == nil && !w.closing {
+		w.err = err
+	}
+	w.closing = true
+	w.mutex.Unlock()
+}
+
+// Deserializes a message into a Context and calls `target.onmessage({data})`, if it exists.
+func deliverMessage(ctx *Context, target *Object, msg *serializedValue) (err error) {
+	defer msg.free()
+	ctx.WithTemporaryValues(func() {
+		var data *Value
+		if data, err = msg.deserialize(ctx); err != nil {
+			return
+		}
+		handler, _ := target.Get("onmessage")
+		if handler == nil || !handler.IsFunction() {
+			return
+		}
+		fn, _ := handler.AsFunction()
+		event := ctx.NewObject()
+		event.Set("type", "message")
+		event.Set("data", data)
+		if _, err = fn.Call(target, event); err != nil {
+			return
+		}
+		ctx.PerformMicrotaskCheckpoint()
+	})
+	return
+}
+
+// The global object template of a worker's Context, with `postMessage` and `close`.
+func workerGlobalTemplate(iso *Isolate, w *Worker) *ObjectTemplate {
+	global := NewObjectTemplate(iso)
+	global.Set("postMessage", NewFunctionTemplate(iso, func(info *FunctionCallbackInfo) *Value {
+		args := info.Args()
+		if len(args) == 0 {
+			return throwError(info.Context(), TypeError, "postMessage requires 1 argument")
+		}
+		transfer, err := transferList(args)
+		if err == nil {
+			if !w.isClosing() {
+				var msg *serializedValue
+				if msg, err = serializeValue(args[0], transfer); err == nil {
+					w.toParent.push(msg)
+				}
+			}
+		}
+		if err != nil {
+			return throwJSError(info.Context(), err)
+		}
+		return nil
+	}))
+	global.Set("close", NewFunctionTemplate(iso, func(info *FunctionCallbackInfo) *Value {
+		w.mutex.Lock()
+		w.closing = true
+		w.mutex.Unlock()
+		return nil
+	}))
+	return global
+}
+
+// Returns the ObjectTemplate for the parent-side JS object of a Worker, creating it on first
+// use. The worker's ID is stored in internal field 0.
+func (i *Isolate) workerTemplate() *ObjectTemplate {
+	if i.workerTmpl == nil {
+		tmpl := NewObjectTemplate(i)
+		tmpl.SetInternalFieldCount(1)
+		tmpl.Set("postMessage", NewFunctionTemplate(i, func(info *FunctionCallbackInfo) *Value {
+			w := workerFromThis(info)
+			if w == nil {
+				return throwError(info.Context(), TypeError, "Illegal invocation")
+			}
+			args := info.Args()
+			if len(args) == 0 {
+				return throwError(info.Context(), TypeError, "postMessage requires 1 argument")
+			}
+			transfer, err := transferList(args)
+			if err == nil {
+				err = w.postMessage(args[0], transfer)
+			}
+			if err != nil {
+				return throwJSError(info.Context(), err)
+			}
+			return nil
+		}))
+		tmpl.Set("terminate", NewFunctionTemplate(i, func(info *FunctionCallbackInfo) *Value {
+			if w := workerFromThis(info); w != nil {
+				w.Terminate()
+			}
+			return nil
+		}))
+		i.workerTmpl = tmpl
+	}
+	return i.workerTmpl
+}
+
+// Finds the Worker whose JS object is the receiver of a callback.
+func workerFromThis(info *FunctionCallbackInfo) *Worker {
+	this := info.This()
+	if this == nil || this.InternalFieldCount() != 1 {
+		return nil
+	}
+	id := this.GetInternalField(0)
+	if !id.IsInt32() {
+		return nil
+	}
+	if w, ok := workers.Load(id.Int32()); ok {
+		return w.(*Worker)
+	}
+	return nil
+}
+
+// Returns the contents of the optional `transfer` array argument of `postMessage`.
+func transferList(args []*Value) ([]*Value, error) {
+	if len(args) < 2 || args[1].IsNullOrUndefined() {
+		return nil, nil
+	}
+	if !args[1].IsArray() {
+		return nil, errors.New("TypeError: postMessage transfer list must be an array")
+	}
+	list, _ := args[1].AsObject()
+	length, _ := list.Get("length")
+	transfer := make([]*Value, length.Uint32())
+	for i := range transfer {
+		item, err := list.GetIdx(uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		transfer[i] = item
+	}
+	return transfer, nil
+}
+
+// messageQueue is a thread-safe queue of serialized messages.
+type messageQueue struct {
+	mutex  sync.Mutex
+	msgs   []*serializedValue
+	signal chan struct{} // Receives a value when a message is pushed
+}
+
+func (q *messageQueue) init() {
+	q.signal = make(chan struct{}, 1)
+}
+
+func (q *messageQueue) push(msg *serializedValue) {
+	q.mutex.Lock()
+	q.msgs = append(q.msgs, msg)
+	q.mutex.Unlock()
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (q *messageQueue) takeAll() []*serializedValue {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	msgs := q.msgs
+	q.msgs = nil
+	return msgs
+}
+
+func (q *messageQueue) hasMessages() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.msgs) > 0
+}
+
+func (q *messageQueue) discard() {
+	for _, msg := range q.takeAll() {
+		msg.free()
+	}
+}
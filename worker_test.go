@@ -0,0 +1,226 @@
+// Copyright 2022 the v8go contributors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package v8go_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v8 "github.com/couchbasedeps/v8go"
+)
+
+// Waits for a message from the worker and dispatches it to the parent's onmessage handler.
+func dispatchOne(t *testing.T, w *v8.Worker) {
+	t.Helper()
+	if !w.WaitForMessage(10 * time.Second) {
+		t.Fatalf("no message from worker (err=%v)", w.Err())
+	}
+	n, err := w.DispatchMessages()
+	fatalIf(t, err)
+	if n == 0 {
+		t.Fatal("expected DispatchMessages to deliver a message")
+	}
+}
+
+func TestWorkerMessages(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	w, err := v8.NewWorker(ctx, `
+		onmessage = e => {
+			const {n, when, tags} = e.data;
+			postMessage({n: n * 2, year: when.getUTCFullYear(), tags: [...tags].sort()});
+		};`, "worker.js")
+	fatalIf(t, err)
+	defer w.Terminate()
+	fatalIf(t, ctx.Global().Set("worker", w.Object()))
+
+	_, err = ctx.RunScript(`
+		var result;
+		worker.onmessage = e => { result = e.data; };
+		worker.postMessage({n: 21, when: new Date(Date.UTC(2022, 0, 1)), tags: new Set(["b", "a"])});`,
+		"main.js")
+	fatalIf(t, err)
+	dispatchOne(t, w)
+
+	val, err := ctx.RunScript("JSON.stringify(result)", "")
+	fatalIf(t, err)
+	if s := val.String(); s != `{"n":42,"year":2022,"tags":["a","b"]}` {
+		t.Errorf("unexpected result: %s", s)
+	}
+
+	// Posting from Go:
+	msg, err := ctx.RunScript("({n: 5, when: new Date(0), tags: []})", "")
+	fatalIf(t, err)
+	fatalIf(t, w.PostMessage(msg))
+	dispatchOne(t, w)
+	val, err = ctx.RunScript("result.n", "")
+	fatalIf(t, err)
+	if val.Int32() != 10 {
+		t.Errorf("unexpected result: %v", val)
+	}
+
+	// Functions can't be cloned:
+	_, err = ctx.RunScript("worker.postMessage(() => 1)", "")
+	if err == nil || !strings.Contains(err.Error(), "could not be cloned") {
+		t.Errorf("expected clone error, got %v", err)
+	}
+}
+
+func TestWorkerTransfer(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	w, err := v8.NewWorker(ctx, `
+		onmessage = e => {
+			const bytes = new Uint8Array(e.data);
+			bytes[0] += 1;
+			postMessage(e.data, [e.data]);
+			postMessage(e.data.byteLength);
+		};`, "worker.js")
+	fatalIf(t, err)
+	defer w.Terminate()
+	fatalIf(t, ctx.Global().Set("worker", w.Object()))
+
+	_, err = ctx.RunScript(`
+		var results = [];
+		worker.onmessage = e => { results.push(e.data); };
+		var buf = new Uint8Array([41, 2, 3]).buffer;
+		worker.postMessage(buf, [buf]);`, "main.js")
+	fatalIf(t, err)
+
+	val, err := ctx.RunScript("buf.byteLength", "")
+	fatalIf(t, err)
+	if val.Int32() != 0 {
+		t.Errorf("expected transferred buffer to be detached, byteLength=%v", val)
+	}
+
+	for received := 0; received < 2; {
+		if !w.WaitForMessage(10 * time.Second) {
+			t.Fatalf("no message from worker (err=%v)", w.Err())
+		}
+		n, err := w.DispatchMessages()
+		fatalIf(t, err)
+		received += n
+	}
+	val, err = ctx.RunScript("[...new Uint8Array(results[0])].join() + ' ' + results[1]", "")
+	fatalIf(t, err)
+	if s := val.String(); s != "42,2,3 0" {
+		t.Errorf("unexpected results: %s", s)
+	}
+
+	_, err = ctx.RunScript("worker.postMessage(1, [{}])", "")
+	if err == nil || !strings.HasPrefix(err.Error(), "TypeError") {
+		t.Errorf("expected TypeError for bad transfer list, got %v", err)
+	}
+}
+
+func TestWorkerSharedArrayBuffer(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	w, err := v8.NewWorker(ctx, `
+		onmessage = e => {
+			Atomics.store(new Int32Array(e.data), 0, 1234);
+			postMessage("done");
+		};`, "worker.js")
+	fatalIf(t, err)
+	defer w.Terminate()
+
+	sab, err := v8.NewSharedArrayBuffer(ctx, 16)
+	fatalIf(t, err)
+	fatalIf(t, w.PostMessage(sab))
+	if !w.WaitForMessage(10 * time.Second) {
+		t.Fatalf("no message from worker (err=%v)", w.Err())
+	}
+	fatalIf(t, ctx.Global().Set("sab", sab))
+	val, err := ctx.RunScript("Atomics.load(new Int32Array(sab), 0)", "")
+	fatalIf(t, err)
+	if val.Int32() != 1234 {
+		t.Errorf("expected worker's write to be visible, got %v", val)
+	}
+}
+
+func TestWorkerClose(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	w, err := v8.NewWorker(ctx, `postMessage("bye"); close();`, "worker.js")
+	fatalIf(t, err)
+	select {
+	case <-w.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("worker didn't exit after close()")
+	}
+	fatalIf(t, w.Err())
+	if !w.WaitForMessage(0) {
+		t.Error("expected message posted before close() to be waiting")
+	}
+}
+
+func TestWorkerTerminate(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	w, err := v8.NewWorker(ctx, `postMessage("started"); while (true) {}`, "worker.js")
+	fatalIf(t, err)
+	if !w.WaitForMessage(10 * time.Second) {
+		t.Fatal("worker didn't start")
+	}
+	w.Terminate()
+	select {
+	case <-w.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("worker didn't exit after Terminate")
+	}
+	if err := w.Err(); err != nil {
+		t.Errorf("expected no error after Terminate, got %v", err)
+	}
+	if n, _ := w.DispatchMessages(); n != 0 {
+		t.Errorf("expected pending messages to be discarded, got %d", n)
+	}
+	// Posting to a terminated worker is a no-op:
+	fatalIf(t, w.PostMessage(v8.Undefined(iso)))
+}
+
+func TestWorkerError(t *testing.T) {
+	t.Parallel()
+	iso := v8.NewIsolate()
+	defer iso.Dispose()
+	ctx := v8.NewContext(iso)
+	defer ctx.Close()
+
+	w, err := v8.NewWorker(ctx, `onmessage = e => { throw new RangeError("nope"); }`, "worker.js")
+	fatalIf(t, err)
+	fatalIf(t, w.PostMessage(v8.Null(iso)))
+	select {
+	case <-w.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("worker didn't exit after an uncaught exception")
+	}
+	if err := w.Err(); err == nil || err.Error() != "RangeError: nope" {
+		t.Errorf("unexpected worker error: %v", err)
+	}
+
+	if _, err := v8.NewWorker(nil, "", ""); err == nil {
+		t.Error("expected error with nil Context")
+	}
+}